@@ -72,3 +72,18 @@ func (self CachedMd5Comparer) cacheAdd(lf *drive.LocalFile, md5 string) {
 func (self CachedMd5Comparer) persist() {
 	writeJson(self.path, self.cache)
 }
+
+// Compares the local file with the plaintext checksum of encrypted remote
+// files, the md5 checksum of drive is of the encrypted content
+type EncryptedMd5Comparer struct {
+	CachedMd5Comparer
+	cipher *drive.Cipher
+}
+
+func NewEncryptedMd5Comparer(path string, cipher *drive.Cipher) EncryptedMd5Comparer {
+	return EncryptedMd5Comparer{NewCachedMd5Comparer(path), cipher}
+}
+
+func (self EncryptedMd5Comparer) Changed(local *drive.LocalFile, remote *drive.RemoteFile) bool {
+	return !remote.HasPlainMd5(self.md5(local), self.cipher)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prasmussen/gdrive/cli"
+	"github.com/prasmussen/gdrive/drive"
+)
+
+const ConfigFilename = "config.json"
+
+type Config struct {
+	Syncs []*SyncProfile `json:"syncs"`
+}
+
+type SyncProfile struct {
+	Name             string   `json:"name"`
+	Path             string   `json:"path"`
+	RootId           string   `json:"rootId"`
+	Direction        string   `json:"direction"`
+	Comparer         string   `json:"comparer"`
+	Resolution       string   `json:"resolution"`
+	DeleteExtraneous bool     `json:"deleteExtraneous"`
+	MaxDelete        string   `json:"maxDelete"`
+	BackupDir        string   `json:"backupDir"`
+	Ignore           []string `json:"ignore"`
+	ChunkSize        int64    `json:"chunkSize"`
+	Timeout          *int64   `json:"timeout"`
+	KeyFile          string   `json:"keyFile"`
+	EncryptNames     bool     `json:"encryptNames"`
+}
+
+func readConfig(configDir string) (*Config, error) {
+	path := ConfigFilePath(configDir, ConfigFilename)
+
+	config := &Config{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open config file: %s", err)
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse config file '%s': %s", path, err)
+	}
+
+	if err = config.validate(); err != nil {
+		return nil, fmt.Errorf("Invalid config file '%s': %s", path, err)
+	}
+
+	return config, nil
+}
+
+func (self *Config) validate() error {
+	names := map[string]bool{}
+
+	for i, p := range self.Syncs {
+		if p.Name == "" {
+			return fmt.Errorf("sync #%d is missing a name", i+1)
+		}
+
+		if names[p.Name] {
+			return fmt.Errorf("sync name '%s' is used more than once", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.Path == "" || p.RootId == "" {
+			return fmt.Errorf("sync '%s' must have both path and rootId", p.Name)
+		}
+
+		if p.Direction != "upload" && p.Direction != "download" {
+			return fmt.Errorf("sync '%s' has invalid direction '%s', must be upload or download", p.Name, p.Direction)
+		}
+
+		switch p.Comparer {
+		case "", "md5", "cachedMd5":
+		default:
+			return fmt.Errorf("sync '%s' has invalid comparer '%s', must be md5 or cachedMd5", p.Name, p.Comparer)
+		}
+
+		switch p.Resolution {
+		case "", "keepLocal", "keepRemote", "keepLargest":
+		default:
+			return fmt.Errorf("sync '%s' has invalid resolution '%s', must be keepLocal, keepRemote or keepLargest", p.Name, p.Resolution)
+		}
+
+		if _, err := drive.ParseDeleteLimit(p.MaxDelete); err != nil {
+			return fmt.Errorf("sync '%s': %s", p.Name, err)
+		}
+
+		if p.BackupDir != "" && p.Direction != "download" {
+			return fmt.Errorf("sync '%s' has a backupDir, which is only supported for downloads", p.Name)
+		}
+
+		if p.EncryptNames && p.KeyFile == "" {
+			return fmt.Errorf("sync '%s' has encryptNames, which requires a keyFile", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func (self *Config) findSync(name string) (*SyncProfile, bool) {
+	for _, p := range self.Syncs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+func (self *SyncProfile) comparer(configDir string, cipher *drive.Cipher) drive.FileComparer {
+	// Encrypted files can only be compared by their plaintext checksum
+	if cipher != nil {
+		return NewEncryptedMd5Comparer(filepath.Join(configDir, DefaultCacheFileName), cipher)
+	}
+
+	if self.Comparer == "md5" {
+		return Md5Comparer{}
+	}
+
+	return NewCachedMd5Comparer(filepath.Join(configDir, DefaultCacheFileName))
+}
+
+func (self *SyncProfile) cipher() (*drive.Cipher, error) {
+	if self.KeyFile == "" {
+		return nil, nil
+	}
+	return drive.NewCipherFromKeyFile(self.KeyFile)
+}
+
+func (self *SyncProfile) resolution() drive.ConflictResolution {
+	switch self.Resolution {
+	case "keepLocal":
+		return drive.KeepLocal
+	case "keepRemote":
+		return drive.KeepRemote
+	case "keepLargest":
+		return drive.KeepLargest
+	}
+
+	return drive.NoResolution
+}
+
+func (self *SyncProfile) chunkSize() int64 {
+	if self.ChunkSize == 0 {
+		return DefaultUploadChunkSize
+	}
+	return self.ChunkSize
+}
+
+func (self *SyncProfile) timeout() time.Duration {
+	if self.Timeout == nil {
+		return durationInSeconds(DefaultTimeout)
+	}
+	return durationInSeconds(*self.Timeout)
+}
+
+func runSyncProfile(d *drive.Drive, p *SyncProfile, configDir string, args cli.Arguments) error {
+	maxDelete, _ := drive.ParseDeleteLimit(p.MaxDelete)
+
+	cipher, err := p.cipher()
+	if err != nil {
+		return err
+	}
+
+	if p.Direction == "download" {
+		return d.DownloadSync(drive.DownloadSyncArgs{
+			Out:              os.Stdout,
+			Progress:         progressWriter(args.Bool("noProgress")),
+			Path:             p.Path,
+			RootId:           p.RootId,
+			DryRun:           args.Bool("dryRun"),
+			DeleteExtraneous: p.DeleteExtraneous,
+			MaxDelete:        maxDelete,
+			BackupDir:        p.BackupDir,
+			Timeout:          p.timeout(),
+			Resolution:       p.resolution(),
+			Comparer:         p.comparer(configDir, cipher),
+			IgnorePatterns:   p.Ignore,
+			Cipher:           cipher,
+		})
+	}
+
+	return d.UploadSync(drive.UploadSyncArgs{
+		Out:              os.Stdout,
+		Progress:         progressWriter(args.Bool("noProgress")),
+		Path:             p.Path,
+		RootId:           p.RootId,
+		DryRun:           args.Bool("dryRun"),
+		DeleteExtraneous: p.DeleteExtraneous,
+		MaxDelete:        maxDelete,
+		ChunkSize:        p.chunkSize(),
+		Timeout:          p.timeout(),
+		Resolution:       p.resolution(),
+		Comparer:         p.comparer(configDir, cipher),
+		IgnorePatterns:   p.Ignore,
+		Cipher:           cipher,
+		EncryptName:      p.EncryptNames,
+	})
+}
+
+func printSyncProfiles(out io.Writer, profiles []*SyncProfile, skipHeader bool) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	if !skipHeader {
+		fmt.Fprintln(w, "Name\tDirection\tPath\tRoot Id\tDelete Extraneous")
+	}
+
+	for _, p := range profiles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n",
+			p.Name,
+			p.Direction,
+			p.Path,
+			p.RootId,
+			p.DeleteExtraneous,
+		)
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,445 @@
+package drive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ArchiveTarGz = "tar.gz"
+	ArchiveZip   = "zip"
+
+	// Uncompressed tar archives can be extracted, but not created
+	archiveTar = "tar"
+)
+
+func archiveMimeType(format string) string {
+	if format == ArchiveZip {
+		return "application/zip"
+	}
+	return "application/gzip"
+}
+
+func checkArchiveFormat(format string) error {
+	if format != ArchiveTarGz && format != ArchiveZip {
+		return fmt.Errorf("Unsupported archive format '%s', must be %s or %s", format, ArchiveTarGz, ArchiveZip)
+	}
+	return nil
+}
+
+// Detects the archive format from the file name, falling back to the mime type
+func detectArchiveFormat(name, mimeType string) (string, error) {
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip, nil
+	}
+
+	switch mimeType {
+	case "application/gzip", "application/x-gzip", "application/x-compressed-tar":
+		return ArchiveTarGz, nil
+	case "application/x-tar":
+		return archiveTar, nil
+	case "application/zip", "application/x-zip-compressed":
+		return ArchiveZip, nil
+	}
+
+	return "", fmt.Errorf("'%s' is not a tar or zip archive", name)
+}
+
+func (self *Drive) uploadArchive(args UploadArgs) error {
+	if err := checkArchiveFormat(args.Archive); err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(args.Path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("Failed stat file: %s", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory, only directories can be archived", args.Path)
+	}
+
+	shouldIgnore, err := prepareIgnorer(filepath.Join(absPath, DefaultIgnoreFile), nil)
+	if err != nil {
+		return err
+	}
+
+	name := args.Name
+	if name == "" {
+		name = info.Name() + "." + args.Archive
+	}
+
+	mimeType := args.Mime
+	if mimeType == "" {
+		mimeType = archiveMimeType(args.Archive)
+	}
+
+	// The archive is written to a pipe while it is being uploaded,
+	// so no temporary file is needed
+	reader, writer := io.Pipe()
+
+	go func() {
+		writer.CloseWithError(writeArchive(writer, absPath, args.Archive, shouldIgnore))
+	}()
+
+	err = self.UploadStream(UploadStreamArgs{
+		Out:         args.Out,
+		In:          reader,
+		Name:        name,
+		Description: args.Description,
+		Parents:     args.Parents,
+		Mime:        mimeType,
+		Share:       args.Share,
+		ChunkSize:   args.ChunkSize,
+		Progress:    args.Progress,
+		Timeout:     args.Timeout,
+		Cipher:      args.Cipher,
+		EncryptName: args.EncryptName,
+		Properties:  args.Properties,
+	})
+
+	// Stop the archive writer if the upload failed
+	reader.CloseWithError(err)
+	return err
+}
+
+type archiveWriter interface {
+	add(name string, info os.FileInfo, absPath string) error
+	Close() error
+}
+
+func writeArchive(w io.Writer, root string, format string, shouldIgnore ignoreFunc) error {
+	var archive archiveWriter
+	if format == ArchiveZip {
+		archive = &zipArchiveWriter{zip.NewWriter(w)}
+	} else {
+		archive = newTarArchiveWriter(w)
+	}
+
+	// Entries are stored below the name of the root directory
+	base := filepath.Base(root)
+
+	err := filepath.Walk(root, func(absPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, absPath)
+		if err != nil {
+			return err
+		}
+
+		if relPath != "." && info.IsDir() && (shouldIgnore(relPath) || shouldIgnore(relPath+"/")) {
+			return filepath.SkipDir
+		}
+
+		if !info.IsDir() && shouldIgnore(relPath) {
+			return nil
+		}
+
+		// Skip files that are not a directory or regular file
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return archive.add(filepath.ToSlash(filepath.Join(base, relPath)), info, absPath)
+	})
+
+	if err != nil {
+		archive.Close()
+		return fmt.Errorf("Failed to write archive: %s", err)
+	}
+
+	return archive.Close()
+}
+
+type tarArchiveWriter struct {
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func newTarArchiveWriter(w io.Writer) *tarArchiveWriter {
+	gz := gzip.NewWriter(w)
+	return &tarArchiveWriter{gz, tar.NewWriter(gz)}
+}
+
+func (self *tarArchiveWriter) add(name string, info os.FileInfo, absPath string) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err = self.tar.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return nil
+	}
+
+	return copyFileTo(self.tar, absPath)
+}
+
+func (self *tarArchiveWriter) Close() error {
+	if err := self.tar.Close(); err != nil {
+		return err
+	}
+	return self.gzip.Close()
+}
+
+type zipArchiveWriter struct {
+	zip *zip.Writer
+}
+
+func (self *zipArchiveWriter) add(name string, info os.FileInfo, absPath string) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+
+	w, err := self.zip.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return nil
+	}
+
+	return copyFileTo(w, absPath)
+}
+
+func (self *zipArchiveWriter) Close() error {
+	return self.zip.Close()
+}
+
+func copyFileTo(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (self *Drive) extractArchive(f *drive.File, args DownloadArgs) error {
+	name, err := plainName(f, args.Cipher)
+	if err != nil {
+		return err
+	}
+
+	format, err := detectArchiveFormat(name, f.MimeType)
+	if err != nil {
+		return err
+	}
+
+	res, body, err := self.getContent(f, args)
+	if err != nil {
+		return err
+	}
+
+	// Close body on function exit
+	defer res.Body.Close()
+
+	body = getProgressReader(body, args.Progress, contentLength(f, res))
+
+	fmt.Fprintf(args.Out, "Extracting %s -> %s\n", name, filepath.Join(args.Path, "."))
+
+	var count int
+	switch format {
+	case ArchiveZip:
+		count, err = extractZip(body, args)
+	case ArchiveTarGz:
+		gz, gzErr := gzip.NewReader(body)
+		if gzErr != nil {
+			return fmt.Errorf("Failed to read archive: %s", gzErr)
+		}
+		count, err = extractTar(gz, args)
+	default:
+		count, err = extractTar(body, args)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(args.Out, "Extracted %d files from %s\n", count, f.Id)
+	return nil
+}
+
+func extractTar(r io.Reader, args DownloadArgs) (int, error) {
+	tr := tar.NewReader(r)
+	count := 0
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("Failed to read archive: %s", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = extractDir(header.Name, args)
+		case tar.TypeReg:
+			err = extractFile(header.Name, tr, header.FileInfo().Mode(), args)
+			count++
+		default:
+			// Links and special files are not supported
+			continue
+		}
+
+		if err != nil {
+			return count, err
+		}
+	}
+}
+
+// Zip archives keep their index at the end of the file, so the archive
+// is downloaded to a temporary file before it is extracted
+func extractZip(r io.Reader, args DownloadArgs) (int, error) {
+	tmpFile, err := ioutil.TempFile("", "gdrive-archive")
+	if err != nil {
+		return 0, fmt.Errorf("Unable to create temporary file: %s", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	size, err := io.Copy(tmpFile, r)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to download archive: %s", err)
+	}
+
+	zr, err := zip.NewReader(tmpFile, size)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read archive: %s", err)
+	}
+
+	count := 0
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			if err = extractDir(zf.Name, args); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return count, fmt.Errorf("Failed to read '%s' from archive: %s", zf.Name, err)
+		}
+
+		err = extractFile(zf.Name, rc, zf.Mode(), args)
+		rc.Close()
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Returns the local path of an archive entry, entries
+// that would end up outside of the download path are rejected
+func extractPath(name string, args DownloadArgs) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Archive entry '%s' points outside of the download path", name)
+	}
+
+	return filepath.Join(args.Path, cleaned), nil
+}
+
+func extractDir(name string, args DownloadArgs) error {
+	fpath, err := extractPath(name, args)
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(fpath, 0775)
+}
+
+func extractFile(name string, r io.Reader, mode os.FileMode, args DownloadArgs) error {
+	fpath, err := extractPath(name, args)
+	if err != nil {
+		return err
+	}
+
+	// Check if file exists to force
+	if !args.Skip && !args.Force && fileExists(fpath) {
+		return fmt.Errorf("File '%s' already exists, use --force to overwrite or --skip to skip", fpath)
+	}
+
+	// Check if file exists to skip
+	if args.Skip && fileExists(fpath) {
+		fmt.Fprintf(args.Out, "File '%s' already exists, skipping\n", fpath)
+		return nil
+	}
+
+	// Ensure any parent directories exists
+	if err := mkdir(fpath); err != nil {
+		return err
+	}
+
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0664
+	}
+
+	tmpPath := fpath + ".incomplete"
+
+	outFile, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("Unable to create new file: %s", err)
+	}
+
+	_, err = io.Copy(outFile, r)
+	outFile.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("Failed to extract '%s': %s", name, err)
+	}
+
+	return os.Rename(tmpPath, fpath)
+}
@@ -0,0 +1,45 @@
+package drive
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractPath(t *testing.T) {
+	args := DownloadArgs{Path: "out"}
+
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"file.txt", "out/file.txt", false},
+		{"dir/file.txt", "out/dir/file.txt", false},
+		{"dir/", "out/dir", false},
+		{"./dir/../file.txt", "out/file.txt", false},
+		{"..file", "out/..file", false},
+		{"dir/../../file.txt", "", true},
+		{"../file.txt", "", true},
+		{"..", "", true},
+		{"/etc/passwd", "", true},
+	}
+
+	for _, c := range cases {
+		got, err := extractPath(c.name, args)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("extractPath(%q) = %q, want error", c.name, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("extractPath(%q) failed: %s", c.name, err)
+			continue
+		}
+
+		if want := filepath.FromSlash(c.want); got != want {
+			t.Errorf("extractPath(%q) = %q, want %q", c.name, got, want)
+		}
+	}
+}
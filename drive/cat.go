@@ -0,0 +1,284 @@
+package drive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const DefaultHeadLines = 10
+
+// Size of the first range requested from the end of a file when looking
+// for the last lines, the range grows until enough lines are found
+const tailChunkSize = 64 * 1024
+
+// Mime types used when printing native documents
+var textExportMime = map[string]string{
+	"application/vnd.google-apps.document":     "text/plain",
+	"application/vnd.google-apps.spreadsheet":  "text/csv",
+	"application/vnd.google-apps.presentation": "text/plain",
+	"application/vnd.google-apps.script":       "application/vnd.google-apps.script+json",
+}
+
+type CatArgs struct {
+	Out io.Writer
+	Id  string
+}
+
+func (self *Drive) Cat(args CatArgs) error {
+	f, err := self.getPrintableFile(args.Id)
+	if err != nil {
+		return err
+	}
+
+	body, _, err := self.openRange(f, "")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(args.Out, body)
+	if err != nil {
+		return fmt.Errorf("Failed to read file: %s", err)
+	}
+	return nil
+}
+
+type HeadArgs struct {
+	Out   io.Writer
+	Id    string
+	Bytes int64
+	Lines int64
+}
+
+func (self *Drive) Head(args HeadArgs) error {
+	f, err := self.getPrintableFile(args.Id)
+	if err != nil {
+		return err
+	}
+
+	byteRange := ""
+	if args.Bytes > 0 {
+		byteRange = fmt.Sprintf("bytes=0-%d", args.Bytes-1)
+	}
+
+	body, _, err := self.openRange(f, byteRange)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if args.Bytes > 0 {
+		// The range is not honored for exported documents
+		_, err = io.Copy(args.Out, io.LimitReader(body, args.Bytes))
+	} else {
+		err = copyLines(args.Out, body, headLines(args.Lines))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to read file: %s", err)
+	}
+	return nil
+}
+
+type TailArgs struct {
+	Out   io.Writer
+	Id    string
+	Bytes int64
+	Lines int64
+}
+
+func (self *Drive) Tail(args TailArgs) error {
+	f, err := self.getPrintableFile(args.Id)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if args.Bytes > 0 {
+		data, err = self.tailBytes(f, args.Bytes)
+	} else {
+		data, err = self.tailLines(f, headLines(args.Lines))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = args.Out.Write(data)
+	return err
+}
+
+func headLines(lines int64) int64 {
+	if lines > 0 {
+		return lines
+	}
+	return DefaultHeadLines
+}
+
+func (self *Drive) getPrintableFile(idOrPath string) (*drive.File, error) {
+	id, err := self.resolveId(idOrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "size", "mimeType", "md5Checksum", "appProperties").Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	if isDir(f) {
+		return nil, fmt.Errorf("'%s' is a directory", f.Name)
+	}
+
+	if isEncrypted(f) {
+		return nil, fmt.Errorf("'%s' is encrypted, use 'download --stdout --key-file' to print it", f.Name)
+	}
+
+	if !isBinary(f) {
+		if _, ok := textExportMime[f.MimeType]; !ok {
+			return nil, fmt.Errorf("'%s' has type %s which can not be printed as text", f.Name, f.MimeType)
+		}
+	}
+
+	return f, nil
+}
+
+// Opens the content of the file, native documents are exported as text.
+// Returns true if only the given range was returned
+func (self *Drive) openRange(f *drive.File, byteRange string) (io.ReadCloser, bool, error) {
+	if !isBinary(f) {
+		res, err := self.service.Files.Export(f.Id, textExportMime[f.MimeType]).Download()
+		if err != nil {
+			return nil, false, fmt.Errorf("Failed to export file: %s", err)
+		}
+		return res.Body, false, nil
+	}
+
+	// A range of an empty file can not be satisfied
+	if f.Size == 0 {
+		return ioutil.NopCloser(&bytes.Buffer{}), true, nil
+	}
+
+	// The service does not support setting headers on media downloads,
+	// so the request is made with the http client
+	urls := googleapi.ResolveRelative(self.service.BasePath, "files/"+url.PathEscape(f.Id)+"?alt=media&supportsAllDrives=true")
+	req, err := http.NewRequest("GET", urls, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	res, err := self.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("Failed to download file: %s", err)
+	}
+
+	if err = googleapi.CheckResponse(res); err != nil {
+		res.Body.Close()
+		return nil, false, fmt.Errorf("Failed to download file: %s", err)
+	}
+
+	return res.Body, res.StatusCode == http.StatusPartialContent, nil
+}
+
+func (self *Drive) tailBytes(f *drive.File, n int64) ([]byte, error) {
+	body, partial, err := self.openRange(f, fmt.Sprintf("bytes=-%d", n))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file: %s", err)
+	}
+
+	if !partial && int64(len(data)) > n {
+		data = data[int64(len(data))-n:]
+	}
+	return data, nil
+}
+
+// Requests growing ranges from the end of the file until
+// the range contains the requested number of lines
+func (self *Drive) tailLines(f *drive.File, n int64) ([]byte, error) {
+	chunk := int64(tailChunkSize)
+
+	for {
+		byteRange := ""
+		if isBinary(f) && chunk < f.Size {
+			byteRange = fmt.Sprintf("bytes=-%d", chunk)
+		}
+
+		body, partial, err := self.openRange(f, byteRange)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read file: %s", err)
+		}
+
+		lines, complete := lastLines(data, n)
+		if complete || !partial || byteRange == "" {
+			return lines, nil
+		}
+
+		chunk *= 4
+	}
+}
+
+// Returns the last n lines of data, and whether data contained
+// more than n lines, i.e. the first returned line is complete
+func lastLines(data []byte, n int64) ([]byte, bool) {
+	// A trailing newline does not start a new line
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+
+	for i := end - 1; i >= 0; i-- {
+		if data[i] != '\n' {
+			continue
+		}
+
+		n--
+		if n == 0 {
+			return data[i+1:], true
+		}
+	}
+
+	return data, false
+}
+
+func copyLines(w io.Writer, r io.Reader, n int64) error {
+	reader := bufio.NewReader(r)
+
+	for i := int64(0); i < n; i++ {
+		line, err := reader.ReadBytes('\n')
+		if _, werr := w.Write(line); werr != nil {
+			return werr
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package drive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLastLines(t *testing.T) {
+	cases := []struct {
+		data     string
+		n        int64
+		want     string
+		complete bool
+	}{
+		{"a\nb\nc\n", 1, "c\n", true},
+		{"a\nb\nc\n", 2, "b\nc\n", true},
+		{"a\nb\nc", 2, "b\nc", true},
+		{"a\nb\nc\n", 3, "a\nb\nc\n", false},
+		{"a\nb\nc\n", 10, "a\nb\nc\n", false},
+		{"a\n\nc\n", 2, "\nc\n", true},
+		{"abc", 1, "abc", false},
+		{"", 1, "", false},
+	}
+
+	for _, c := range cases {
+		got, complete := lastLines([]byte(c.data), c.n)
+		if string(got) != c.want || complete != c.complete {
+			t.Errorf("lastLines(%q, %d) = %q, %t, want %q, %t", c.data, c.n, got, complete, c.want, c.complete)
+		}
+	}
+}
+
+func TestCopyLines(t *testing.T) {
+	cases := []struct {
+		data string
+		n    int64
+		want string
+	}{
+		{"a\nb\nc\n", 0, ""},
+		{"a\nb\nc\n", 2, "a\nb\n"},
+		{"a\nb\nc", 3, "a\nb\nc"},
+		{"a\nb\nc\n", 10, "a\nb\nc\n"},
+		{"", 1, ""},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		if err := copyLines(&out, strings.NewReader(c.data), c.n); err != nil {
+			t.Errorf("copyLines(%q, %d) failed: %s", c.data, c.n, err)
+			continue
+		}
+
+		if got := out.String(); got != c.want {
+			t.Errorf("copyLines(%q, %d) = %q, want %q", c.data, c.n, got, c.want)
+		}
+	}
+}
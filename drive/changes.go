@@ -27,7 +27,12 @@ func (self *Drive) ListChanges(args ListChangesArgs) error {
 		return nil
 	}
 
-	changeList, err := self.service.Changes.List(args.PageToken).PageSize(args.MaxChanges).RestrictToMyDrive(true).Fields("newStartPageToken", "nextPageToken", "changes(fileId,removed,time,file(id,name,md5Checksum,mimeType,createdTime,modifiedTime))").Do()
+	call := self.service.Changes.List(args.PageToken).PageSize(args.MaxChanges).SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+	if self.sharedDriveId != "" {
+		call = call.DriveId(self.sharedDriveId)
+	}
+
+	changeList, err := call.Fields("newStartPageToken", "nextPageToken", "changes(fileId,removed,time,file(id,name,md5Checksum,mimeType,createdTime,modifiedTime))").Do()
 	if err != nil {
 		return fmt.Errorf("Failed listing changes: %s", err)
 	}
@@ -43,7 +48,12 @@ func (self *Drive) ListChanges(args ListChangesArgs) error {
 }
 
 func (self *Drive) GetChangesStartPageToken() (string, error) {
-	res, err := self.service.Changes.GetStartPageToken().Do()
+	call := self.service.Changes.GetStartPageToken().SupportsAllDrives(true)
+	if self.sharedDriveId != "" {
+		call = call.DriveId(self.sharedDriveId)
+	}
+
+	res, err := call.Do()
 	if err != nil {
 		return "", fmt.Errorf("Failed getting start page token: %s", err)
 	}
@@ -0,0 +1,266 @@
+package drive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const commentFields = "comments(id,author(displayName,emailAddress),content,createdTime,modifiedTime,resolved,anchor,quotedFileContent,replies(id,author(displayName,emailAddress),content,createdTime,action))"
+
+type ListCommentsArgs struct {
+	Out          io.Writer
+	Id           string
+	Unresolved   bool
+	Format       string
+	ContentWidth int64
+	SkipHeader   bool
+}
+
+func (self *Drive) ListComments(args ListCommentsArgs) error {
+	if err := checkOutputFormat(args.Format); err != nil {
+		return err
+	}
+
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	var comments []*drive.Comment
+
+	err = self.service.Comments.List(id).PageSize(100).Fields("nextPageToken", commentFields).Pages(context.TODO(), func(cl *drive.CommentList) error {
+		for _, c := range cl.Comments {
+			if !args.Unresolved || !c.Resolved {
+				comments = append(comments, c)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to list comments: %s", err)
+	}
+
+	switch args.Format {
+	case FormatCsv:
+		return printCommentsCsv(args.Out, comments, args.SkipHeader)
+	case FormatJson:
+		return printJson(args.Out, toCommentsJson(comments))
+	}
+
+	printCommentsTable(args.Out, comments, args)
+	return nil
+}
+
+type AddCommentArgs struct {
+	Out     io.Writer
+	Id      string
+	Content string
+	Anchor  string
+}
+
+// The anchor is a json region of the file as described in the drive
+// documentation, it is not used by google docs editors
+func (self *Drive) AddComment(args AddCommentArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	comment := &drive.Comment{
+		Content: args.Content,
+		Anchor:  args.Anchor,
+	}
+
+	c, err := self.service.Comments.Create(id, comment).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to add comment: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Comment %s added\n", c.Id)
+	return nil
+}
+
+type ReplyCommentArgs struct {
+	Out       io.Writer
+	Id        string
+	CommentId string
+	Content   string
+	Resolve   bool
+}
+
+// Replies to the comment, resolving a comment is done with a reply
+// which may be without content
+func (self *Drive) ReplyComment(args ReplyCommentArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	reply := &drive.Reply{Content: args.Content}
+	if args.Resolve {
+		reply.Action = "resolve"
+	}
+
+	r, err := self.service.Replies.Create(id, args.CommentId, reply).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to reply to comment: %s", err)
+	}
+
+	if args.Resolve {
+		fmt.Fprintf(args.Out, "Comment %s resolved\n", args.CommentId)
+	} else {
+		fmt.Fprintf(args.Out, "Reply %s added\n", r.Id)
+	}
+	return nil
+}
+
+type DeleteCommentArgs struct {
+	Out       io.Writer
+	Id        string
+	CommentId string
+}
+
+func (self *Drive) DeleteComment(args DeleteCommentArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	err = self.service.Comments.Delete(id, args.CommentId).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to delete comment: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Deleted comment %s\n", args.CommentId)
+	return nil
+}
+
+type commentJson struct {
+	Id       string       `json:"id"`
+	Author   string       `json:"author"`
+	Email    string       `json:"email,omitempty"`
+	Created  string       `json:"created"`
+	Modified string       `json:"modified"`
+	Resolved bool         `json:"resolved"`
+	Anchor   string       `json:"anchor,omitempty"`
+	Quote    string       `json:"quote,omitempty"`
+	Content  string       `json:"content"`
+	Replies  []*replyJson `json:"replies"`
+}
+
+type replyJson struct {
+	Id      string `json:"id"`
+	Author  string `json:"author"`
+	Email   string `json:"email,omitempty"`
+	Created string `json:"created"`
+	Action  string `json:"action,omitempty"`
+	Content string `json:"content"`
+}
+
+func toCommentsJson(comments []*drive.Comment) []*commentJson {
+	result := make([]*commentJson, 0, len(comments))
+
+	for _, c := range comments {
+		cj := &commentJson{
+			Id:       c.Id,
+			Author:   authorName(c.Author),
+			Created:  c.CreatedTime,
+			Modified: c.ModifiedTime,
+			Resolved: c.Resolved,
+			Anchor:   c.Anchor,
+			Content:  c.Content,
+			Replies:  make([]*replyJson, 0, len(c.Replies)),
+		}
+
+		if c.Author != nil {
+			cj.Email = c.Author.EmailAddress
+		}
+
+		if c.QuotedFileContent != nil {
+			cj.Quote = c.QuotedFileContent.Value
+		}
+
+		for _, r := range c.Replies {
+			rj := &replyJson{
+				Id:      r.Id,
+				Author:  authorName(r.Author),
+				Created: r.CreatedTime,
+				Action:  r.Action,
+				Content: r.Content,
+			}
+
+			if r.Author != nil {
+				rj.Email = r.Author.EmailAddress
+			}
+
+			cj.Replies = append(cj.Replies, rj)
+		}
+
+		result = append(result, cj)
+	}
+
+	return result
+}
+
+func authorName(u *drive.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.DisplayName
+}
+
+func printCommentsTable(out io.Writer, comments []*drive.Comment, args ListCommentsArgs) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	if !args.SkipHeader {
+		fmt.Fprintln(w, "Id\tAuthor\tCreated\tResolved\tReplies\tContent")
+	}
+
+	for _, c := range comments {
+		// Keep each comment on a single line
+		content := strings.Join(strings.Fields(c.Content), " ")
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			c.Id,
+			authorName(c.Author),
+			formatDatetime(c.CreatedTime),
+			formatBool(c.Resolved),
+			len(c.Replies),
+			truncateString(content, int(args.ContentWidth)),
+		)
+	}
+
+	w.Flush()
+}
+
+func printCommentsCsv(out io.Writer, comments []*drive.Comment, skipHeader bool) error {
+	w := csv.NewWriter(out)
+
+	if !skipHeader {
+		w.Write([]string{"id", "author", "created", "resolved", "replies", "content"})
+	}
+
+	for _, c := range comments {
+		w.Write([]string{
+			c.Id,
+			authorName(c.Author),
+			c.CreatedTime,
+			strconv.FormatBool(c.Resolved),
+			strconv.Itoa(len(c.Replies)),
+			c.Content,
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Failed to write csv: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"path/filepath"
+)
+
+const DefaultCopyParallel = 4
+
+// Native types that can not be copied by drive
+var nonCopyableMimes = map[string]bool{
+	"application/vnd.google-apps.form":        true,
+	"application/vnd.google-apps.site":        true,
+	"application/vnd.google-apps.map":         true,
+	"application/vnd.google-apps.fusiontable": true,
+	"application/vnd.google-apps.jam":         true,
+}
+
+type CopyArgs struct {
+	Out       io.Writer
+	Id        string
+	ParentId  string
+	Name      string
+	Recursive bool
+	Export    bool
+	StripSync bool
+	Parallel  int
+}
+
+func (self *Drive) Copy(args CopyArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	parentId, err := self.resolveId(args.ParentId)
+	if err != nil {
+		return err
+	}
+
+	isSyncDir, err := self.isSyncFile(parentId)
+	if err != nil {
+		return err
+	}
+
+	if isSyncDir {
+		return fmt.Errorf("%s is a sync directory, use 'sync upload' instead", parentId)
+	}
+
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	name := f.Name
+	if args.Name != "" {
+		name = args.Name
+	}
+
+	if !isDir(f) {
+		res := self.copyFile(copyJob{file: f, name: name, parentId: parentId, path: name}, args, 0)
+		if res.err != nil {
+			return res.err
+		}
+
+		if res.skipped && args.Export {
+			return fmt.Errorf("'%s' has type %s which can neither be copied nor exported", f.Name, f.MimeType)
+		}
+
+		if res.skipped {
+			return fmt.Errorf("'%s' has type %s which can not be copied, use --export to copy an exported version", f.Name, f.MimeType)
+		}
+
+		fmt.Fprintf(args.Out, "Copied '%s' to %s\n", f.Name, res.id)
+		return nil
+	}
+
+	if !args.Recursive {
+		return fmt.Errorf("'%s' is a directory, use --recursive to copy directories", f.Name)
+	}
+
+	inside, err := self.isSelfOrDescendant(parentId, f.Id)
+	if err != nil {
+		return err
+	}
+
+	if inside {
+		return fmt.Errorf("Can not copy '%s' into itself", f.Name)
+	}
+
+	return self.copyDirectory(f, name, parentId, args)
+}
+
+// Returns true if the file is the directory or one of its descendants
+func (self *Drive) isSelfOrDescendant(fileId, dirId string) (bool, error) {
+	seen := map[string]bool{}
+	ids := []string{fileId}
+
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+
+		if id == dirId {
+			return true, nil
+		}
+
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("parents").Do()
+		if err != nil {
+			return false, fmt.Errorf("Failed to get file: %s", err)
+		}
+		ids = append(ids, f.Parents...)
+	}
+
+	return false, nil
+}
+
+type copyJob struct {
+	file     *drive.File
+	name     string
+	parentId string
+	path     string
+}
+
+type copyResult struct {
+	job      copyJob
+	id       string
+	exported bool
+	skipped  bool
+	err      error
+}
+
+func (self *Drive) copyDirectory(dir *drive.File, name, parentId string, args CopyArgs) error {
+	fmt.Fprintf(args.Out, "Creating directory hierarchy of '%s'\n", dir.Name)
+
+	// Directories are created up front, so the files can be copied in any order
+	rootId, jobs, err := self.createCopyHierarchy(dir, name, parentId, name)
+	if err != nil {
+		return err
+	}
+
+	parallel := args.Parallel
+	if parallel < 1 {
+		parallel = DefaultCopyParallel
+	}
+
+	jobCh := make(chan copyJob)
+	resultCh := make(chan copyResult)
+
+	for i := 0; i < parallel; i++ {
+		go func() {
+			for job := range jobCh {
+				resultCh <- self.copyFile(job, args, 0)
+			}
+		}()
+	}
+
+	go func() {
+		for _, job := range jobs {
+			jobCh <- job
+		}
+		close(jobCh)
+	}()
+
+	var copied, skipped, failed int
+	total := len(jobs)
+
+	for i := 0; i < total; i++ {
+		res := <-resultCh
+
+		switch {
+		case res.err != nil:
+			failed++
+			fmt.Fprintf(args.Out, "[%04d/%04d] Failed to copy %s: %s\n", i+1, total, res.job.path, res.err)
+		case res.skipped:
+			skipped++
+			fmt.Fprintf(args.Out, "[%04d/%04d] Skipping %s (%s can not be copied)\n", i+1, total, res.job.path, res.job.file.MimeType)
+		case res.exported:
+			copied++
+			fmt.Fprintf(args.Out, "[%04d/%04d] Exported %s\n", i+1, total, res.job.path)
+		default:
+			copied++
+			fmt.Fprintf(args.Out, "[%04d/%04d] Copied %s\n", i+1, total, res.job.path)
+		}
+	}
+
+	fmt.Fprintf(args.Out, "Copied %d files to %s, %d skipped, %d failed\n", copied, rootId, skipped, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("Failed to copy %d files", failed)
+	}
+
+	return nil
+}
+
+// Recreates the directory and all its subdirectories below the given parent,
+// and returns the id of the new directory and the files that should be copied
+func (self *Drive) createCopyHierarchy(dir *drive.File, name, parentId, path string) (string, []copyJob, error) {
+	// List the content before creating the new directory, so
+	// the new directory never is part of what is copied
+	listArgs := listAllFilesArgs{
+		query:  fmt.Sprintf("'%s' in parents and trashed = false", dir.Id),
+		fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType)"},
+	}
+	files, err := self.listAllFiles(listArgs)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed listing files: %s", err)
+	}
+
+	newDir, err := self.mkdir(MkdirArgs{
+		Name:    name,
+		Parents: []string{parentId},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var jobs []copyJob
+
+	for _, f := range files {
+		childPath := filepath.Join(path, f.Name)
+
+		if !isDir(f) {
+			jobs = append(jobs, copyJob{file: f, name: f.Name, parentId: newDir.Id, path: childPath})
+			continue
+		}
+
+		_, childJobs, err := self.createCopyHierarchy(f, f.Name, newDir.Id, childPath)
+		if err != nil {
+			return "", nil, err
+		}
+		jobs = append(jobs, childJobs...)
+	}
+
+	return newDir.Id, jobs, nil
+}
+
+func (self *Drive) copyFile(job copyJob, args CopyArgs, try int) copyResult {
+	if nonCopyableMimes[job.file.MimeType] {
+		if !args.Export {
+			return copyResult{job: job, skipped: true}
+		}
+		return self.exportCopy(job)
+	}
+
+	dstFile := &drive.File{
+		Name:    job.name,
+		Parents: []string{job.parentId},
+	}
+
+	// The encryption properties are always kept, the copy could not be decrypted without them
+	if args.StripSync {
+		dstFile.NullFields = []string{"AppProperties.sync", "AppProperties.syncRoot", "AppProperties.syncRootId"}
+	}
+
+	f, err := self.service.Files.Copy(job.file.Id, dstFile).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
+			exponentialBackoffSleep(try)
+			try++
+			return self.copyFile(job, args, try)
+		}
+		return copyResult{job: job, err: fmt.Errorf("Failed to copy file: %s", err)}
+	}
+
+	return copyResult{job: job, id: f.Id}
+}
+
+// Exports the file with the default export mime and uploads the result
+func (self *Drive) exportCopy(job copyJob) copyResult {
+	exportMime, ok := DefaultExportMime[job.file.MimeType]
+	if !ok {
+		return copyResult{job: job, skipped: true}
+	}
+
+	res, err := self.service.Files.Export(job.file.Id, exportMime).Download()
+	if err != nil {
+		return copyResult{job: job, err: fmt.Errorf("Failed to export file: %s", err)}
+	}
+
+	// Close body on function exit
+	defer res.Body.Close()
+
+	dstFile := &drive.File{
+		Name:     getExportFilename(job.name, exportMime),
+		Parents:  []string{job.parentId},
+		MimeType: exportMime,
+	}
+
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Fields("id").Media(res.Body).Do()
+	if err != nil {
+		return copyResult{job: job, err: fmt.Errorf("Failed to upload exported file: %s", err)}
+	}
+
+	return copyResult{job: job, id: f.Id, exported: true}
+}
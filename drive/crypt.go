@@ -0,0 +1,339 @@
+package drive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"hash"
+	"io"
+	"io/ioutil"
+)
+
+const EncryptedMimeType = "application/octet-stream"
+
+// App properties used to tag encrypted files
+const (
+	EncryptedProperty     = "encrypted"
+	EncryptedNameProperty = "encryptedName"
+	PlainChecksumProperty = "plainChecksum"
+)
+
+const MinKeySize = 32
+
+// Encrypted content is laid out as a header followed by a sequence of
+// segments, each sealed separately with AES-256-GCM. The nonce of a segment
+// is its sequence number and a flag marking the final segment, so segments
+// can not be reordered, dropped or truncated without failing authentication.
+const (
+	encryptionVersion   = "v1"
+	encryptionMagic     = "gdenc\x00\x00\x01"
+	encryptionSaltSize  = 16
+	encryptionHeaderLen = len(encryptionMagic) + encryptionSaltSize
+	segmentSize         = 64 * 1024
+	segmentOverhead     = 16
+)
+
+type Cipher struct {
+	contentKey   []byte
+	nameKey      []byte
+	nameNonceKey []byte
+	checksumKey  []byte
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("Key must be at least %d bytes, got %d", MinKeySize, len(key))
+	}
+
+	return &Cipher{
+		contentKey:   hmacSum(key, []byte("gdrive content "+encryptionVersion)),
+		nameKey:      hmacSum(key, []byte("gdrive name "+encryptionVersion)),
+		nameNonceKey: hmacSum(key, []byte("gdrive name nonce "+encryptionVersion)),
+		checksumKey:  hmacSum(key, []byte("gdrive checksum "+encryptionVersion)),
+	}, nil
+}
+
+func NewCipherFromKeyFile(path string) (*Cipher, error) {
+	key, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read key file: %s", err)
+	}
+
+	return NewCipher(key)
+}
+
+// EncryptName deterministically encrypts a file name, the same name always
+// gives the same result so that encrypted paths can be compared when syncing
+func (self *Cipher) EncryptName(name string) string {
+	nonce := hmacSum(self.nameNonceKey, []byte(name))[:12]
+	aead := newAead(self.nameKey)
+	sealed := aead.Seal(nonce, nonce, []byte(name), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+func (self *Cipher) DecryptName(name string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(name)
+	if err != nil || len(sealed) < 12 {
+		return "", fmt.Errorf("Failed to decrypt name '%s': invalid format", name)
+	}
+
+	aead := newAead(self.nameKey)
+	plain, err := aead.Open(nil, sealed[:12], sealed[12:], nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed to decrypt name '%s': wrong key or corrupted name", name)
+	}
+
+	return string(plain), nil
+}
+
+// PlainChecksum returns a keyed checksum of the plaintext from its md5, it is
+// stored with encrypted files since a bare md5 would let anyone with access
+// to the file confirm guesses of its content
+func (self *Cipher) PlainChecksum(md5 string) string {
+	return fmt.Sprintf("%x", hmacSum(self.checksumKey, []byte(md5)))
+}
+
+func (self *Cipher) EncryptReader(r io.Reader) (*EncryptReader, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("Failed to generate salt: %s", err)
+	}
+
+	header := append([]byte(encryptionMagic), salt...)
+
+	return &EncryptReader{
+		cipher: self,
+		reader: bufio.NewReaderSize(r, segmentSize+1),
+		aead:   newAead(hmacSum(self.contentKey, salt)),
+		md5:    md5.New(),
+		buffer: bytes.NewBuffer(header),
+		plain:  make([]byte, segmentSize),
+	}, nil
+}
+
+func (self *Cipher) DecryptReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, segmentSize+segmentOverhead+1)
+
+	header := make([]byte, encryptionHeaderLen)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("Failed to read encryption header: %s", err)
+	}
+
+	if string(header[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, fmt.Errorf("File is not encrypted by gdrive or uses an unsupported format")
+	}
+
+	salt := header[len(encryptionMagic):]
+
+	return &decryptReader{
+		reader: br,
+		aead:   newAead(hmacSum(self.contentKey, salt)),
+		sealed: make([]byte, segmentSize+segmentOverhead),
+	}, nil
+}
+
+// MaybeDecryptReader decrypts the content if it starts with an encryption
+// header, and returns it untouched otherwise
+func (self *Cipher) MaybeDecryptReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, segmentSize+segmentOverhead+1)
+
+	magic, _ := br.Peek(len(encryptionMagic))
+	if string(magic) != encryptionMagic {
+		return br, nil
+	}
+
+	return self.DecryptReader(br)
+}
+
+type EncryptReader struct {
+	cipher  *Cipher
+	reader  *bufio.Reader
+	aead    cipher.AEAD
+	md5     hash.Hash
+	buffer  *bytes.Buffer
+	plain   []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func (self *EncryptReader) Read(p []byte) (int, error) {
+	for self.buffer.Len() == 0 {
+		if self.done {
+			return 0, io.EOF
+		}
+
+		if self.err != nil {
+			return 0, self.err
+		}
+
+		self.sealSegment()
+	}
+
+	return self.buffer.Read(p)
+}
+
+// PlainChecksum returns the keyed checksum of the plaintext read so far
+func (self *EncryptReader) PlainChecksum() string {
+	return self.cipher.PlainChecksum(fmt.Sprintf("%x", self.md5.Sum(nil)))
+}
+
+func (self *EncryptReader) sealSegment() {
+	n, err := io.ReadFull(self.reader, self.plain)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		self.err = err
+		return
+	}
+
+	// The segment is final if there is nothing more to read
+	final := err != nil
+	if !final {
+		if _, err := self.reader.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			self.err = err
+			return
+		}
+	}
+
+	self.md5.Write(self.plain[:n])
+	self.buffer.Write(self.aead.Seal(nil, segmentNonce(self.counter, final), self.plain[:n], nil))
+	self.counter++
+	self.done = final
+}
+
+type decryptReader struct {
+	reader  *bufio.Reader
+	aead    cipher.AEAD
+	sealed  []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+func (self *decryptReader) Read(p []byte) (int, error) {
+	for len(self.plain) == 0 {
+		if self.done {
+			return 0, io.EOF
+		}
+
+		if err := self.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, self.plain)
+	self.plain = self.plain[n:]
+	return n, nil
+}
+
+func (self *decryptReader) openSegment() error {
+	n, err := io.ReadFull(self.reader, self.sealed)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	final := err != nil
+	if !final {
+		if _, err := self.reader.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := self.aead.Open(nil, segmentNonce(self.counter, final), self.sealed[:n], nil)
+	if err != nil {
+		return fmt.Errorf("Failed to decrypt file: wrong key, or the file is truncated or corrupted")
+	}
+
+	self.plain = plain
+	self.counter++
+	self.done = final
+	return nil
+}
+
+func segmentNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Calculates the plaintext size from the size of the encrypted content,
+// every segment adds a fixed overhead
+func plainSize(encryptedSize int64) int64 {
+	sealed := encryptedSize - int64(encryptionHeaderLen)
+	segments := (sealed + segmentSize + segmentOverhead - 1) / (segmentSize + segmentOverhead)
+	if size := sealed - segments*segmentOverhead; size > 0 {
+		return size
+	}
+	return 0
+}
+
+func newAead(key []byte) cipher.AEAD {
+	// Keys are always 32 bytes long, so these calls can not fail
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	return aead
+}
+
+func hmacSum(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func isEncrypted(f *drive.File) bool {
+	_, ok := f.AppProperties[EncryptedProperty]
+	return ok
+}
+
+func hasEncryptedName(f *drive.File) bool {
+	_, ok := f.AppProperties[EncryptedNameProperty]
+	return ok
+}
+
+// Returns the plaintext name of the file
+func plainName(f *drive.File, c *Cipher) (string, error) {
+	if !hasEncryptedName(f) {
+		return f.Name, nil
+	}
+
+	if c == nil {
+		return "", fmt.Errorf("The name of %s is encrypted, a key file is required", f.Id)
+	}
+
+	return c.DecryptName(f.Name)
+}
+
+func encryptedAppProperties(encryptName bool) map[string]string {
+	props := map[string]string{EncryptedProperty: encryptionVersion}
+	if encryptName {
+		props[EncryptedNameProperty] = "true"
+	}
+	return props
+}
+
+func (self *Drive) setPlainChecksum(fileId, checksum string) error {
+	dstFile := &drive.File{
+		AppProperties: map[string]string{PlainChecksumProperty: checksum},
+	}
+
+	_, err := self.service.Files.Update(fileId, dstFile).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to save plaintext checksum: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package drive
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func testCipher(t *testing.T, key string) *Cipher {
+	c, err := NewCipher([]byte(key))
+	if err != nil {
+		t.Fatalf("NewCipher failed: %s", err)
+	}
+	return c
+}
+
+func encrypt(t *testing.T, c *Cipher, plain []byte) ([]byte, *EncryptReader) {
+	r, err := c.EncryptReader(bytes.NewReader(plain))
+	if err != nil {
+		t.Fatalf("EncryptReader failed: %s", err)
+	}
+
+	sealed, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Encryption failed: %s", err)
+	}
+	return sealed, r
+}
+
+func TestNewCipherKeySize(t *testing.T) {
+	if _, err := NewCipher(make([]byte, MinKeySize-1)); err == nil {
+		t.Errorf("NewCipher accepted a key of %d bytes", MinKeySize-1)
+	}
+
+	if _, err := NewCipher(make([]byte, MinKeySize)); err != nil {
+		t.Errorf("NewCipher rejected a key of %d bytes: %s", MinKeySize, err)
+	}
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := testCipher(t, "0123456789abcdef0123456789abcdef")
+
+	sizes := []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3*segmentSize + 17}
+
+	for _, size := range sizes {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(i * 7)
+		}
+
+		sealed, encryptReader := encrypt(t, c, plain)
+
+		if got := plainSize(int64(len(sealed))); got != int64(size) {
+			t.Errorf("plainSize(%d) = %d, want %d", len(sealed), got, size)
+		}
+
+		wantChecksum := c.PlainChecksum(fmt.Sprintf("%x", md5.Sum(plain)))
+		if got := encryptReader.PlainChecksum(); got != wantChecksum {
+			t.Errorf("PlainChecksum of %d bytes = %s, want %s", size, got, wantChecksum)
+		}
+
+		r, err := c.DecryptReader(bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatalf("DecryptReader of %d bytes failed: %s", size, err)
+		}
+
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Decryption of %d bytes failed: %s", size, err)
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("Round trip of %d bytes returned different content", size)
+		}
+	}
+}
+
+func TestCipherDecryptFailures(t *testing.T) {
+	c := testCipher(t, "0123456789abcdef0123456789abcdef")
+	other := testCipher(t, "fedcba9876543210fedcba9876543210")
+
+	plain := bytes.Repeat([]byte("gdrive"), segmentSize/3)
+	sealed, _ := encrypt(t, c, plain)
+
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	// Dropping the final segment must be detected
+	truncated := sealed[:encryptionHeaderLen+segmentSize+segmentOverhead]
+
+	cases := []struct {
+		name   string
+		cipher *Cipher
+		data   []byte
+	}{
+		{"wrong key", other, sealed},
+		{"tampered", c, tampered},
+		{"truncated", c, truncated},
+	}
+
+	for _, tc := range cases {
+		r, err := tc.cipher.DecryptReader(bytes.NewReader(tc.data))
+		if err == nil {
+			_, err = ioutil.ReadAll(r)
+		}
+
+		if err == nil {
+			t.Errorf("Decrypting %s content succeeded", tc.name)
+		}
+	}
+
+	if _, err := c.DecryptReader(bytes.NewReader([]byte("not encrypted content"))); err == nil {
+		t.Errorf("DecryptReader accepted content without header")
+	}
+}
+
+func TestMaybeDecryptReader(t *testing.T) {
+	c := testCipher(t, "0123456789abcdef0123456789abcdef")
+
+	plain := []byte("plain content")
+	sealed, _ := encrypt(t, c, plain)
+
+	for _, data := range [][]byte{plain, sealed} {
+		r, err := c.MaybeDecryptReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("MaybeDecryptReader failed: %s", err)
+		}
+
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("MaybeDecryptReader read failed: %s", err)
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("MaybeDecryptReader returned %q, want %q", got, plain)
+		}
+	}
+}
+
+func TestCipherNames(t *testing.T) {
+	c := testCipher(t, "0123456789abcdef0123456789abcdef")
+	other := testCipher(t, "fedcba9876543210fedcba9876543210")
+
+	for _, name := range []string{"a", "report.pdf", "IMG_0001.JPG", "æøå"} {
+		encrypted := c.EncryptName(name)
+
+		if encrypted != c.EncryptName(name) {
+			t.Errorf("EncryptName(%q) is not deterministic", name)
+		}
+
+		got, err := c.DecryptName(encrypted)
+		if err != nil || got != name {
+			t.Errorf("DecryptName(EncryptName(%q)) = %q, %v", name, got, err)
+		}
+
+		if _, err := other.DecryptName(encrypted); err == nil {
+			t.Errorf("DecryptName of %q succeeded with the wrong key", name)
+		}
+	}
+}
+
+func TestPlainChecksumIsKeyed(t *testing.T) {
+	c := testCipher(t, "0123456789abcdef0123456789abcdef")
+	other := testCipher(t, "fedcba9876543210fedcba9876543210")
+
+	md5 := fmt.Sprintf("%x", md5.Sum([]byte("content")))
+
+	if c.PlainChecksum(md5) == md5 {
+		t.Errorf("PlainChecksum returned the md5 itself")
+	}
+
+	if c.PlainChecksum(md5) == other.PlainChecksum(md5) {
+		t.Errorf("PlainChecksum is the same for different keys")
+	}
+}
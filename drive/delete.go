@@ -2,6 +2,7 @@ package drive
 
 import (
 	"fmt"
+	"google.golang.org/api/drive/v3"
 	"io"
 )
 
@@ -9,10 +10,11 @@ type DeleteArgs struct {
 	Out       io.Writer
 	Id        string
 	Recursive bool
+	Permanent bool
 }
 
 func (self *Drive) Delete(args DeleteArgs) error {
-	f, err := self.service.Files.Get(args.Id).Fields("name", "mimeType").Do()
+	f, err := self.service.Files.Get(args.Id).SupportsAllDrives(true).Fields("name", "mimeType").Do()
 	if err != nil {
 		return fmt.Errorf("Failed to get file: %s", err)
 	}
@@ -21,19 +23,42 @@ func (self *Drive) Delete(args DeleteArgs) error {
 		return fmt.Errorf("'%s' is a directory, use the 'recursive' flag to delete directories", f.Name)
 	}
 
-	err = self.service.Files.Delete(args.Id).Do()
+	if args.Permanent {
+		err = self.deleteFile(args.Id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(args.Out, "Deleted '%s'\n", f.Name)
+		return nil
+	}
+
+	err = self.trashFile(args.Id)
 	if err != nil {
-		return fmt.Errorf("Failed to delete file: %s", err)
+		return err
 	}
 
-	fmt.Fprintf(args.Out, "Deleted '%s'\n", f.Name)
+	fmt.Fprintf(args.Out, "Moved '%s' to trash\n", f.Name)
 	return nil
 }
 
 func (self *Drive) deleteFile(fileId string) error {
-	err := self.service.Files.Delete(fileId).Do()
+	err := self.service.Files.Delete(fileId).SupportsAllDrives(true).Do()
+	if self.sharedDriveId != "" && isInsufficientPermissionsError(err) {
+		return fmt.Errorf("Failed to delete file, deleting files in a shared drive requires the organizer role: %s", err)
+	}
 	if err != nil {
 		return fmt.Errorf("Failed to delete file: %s", err)
 	}
 	return nil
 }
+
+func (self *Drive) trashFile(fileId string) error {
+	dstFile := &drive.File{Trashed: true}
+
+	_, err := self.service.Files.Update(fileId, dstFile).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to trash file: %s", err)
+	}
+	return nil
+}
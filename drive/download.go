@@ -3,6 +3,7 @@ package drive
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,28 +13,48 @@ import (
 )
 
 type DownloadArgs struct {
-	Out       io.Writer
-	Progress  io.Writer
-	Id        string
-	Path      string
-	Force     bool
-	Skip      bool
-	Recursive bool
-	Delete    bool
-	Stdout    bool
-	Timeout   time.Duration
+	Out             io.Writer
+	Progress        io.Writer
+	Id              string
+	Path            string
+	Force           bool
+	Skip            bool
+	Recursive       bool
+	Delete          bool
+	Stdout          bool
+	Timeout         time.Duration
+	Cipher          *Cipher
+	Extract         bool
+	FollowShortcuts bool
+
+	// Ids of the directories being downloaded, used to
+	// detect shortcuts pointing to one of their ancestors
+	ancestors map[string]bool
 }
 
+var downloadFileFields = []googleapi.Field{"id", "name", "size", "mimeType", "md5Checksum", "appProperties", "shortcutDetails"}
+
 func (self *Drive) Download(args DownloadArgs) error {
 	if args.Recursive {
 		return self.downloadRecursive(args)
 	}
 
-	f, err := self.service.Files.Get(args.Id).Fields("id", "name", "size", "mimeType", "md5Checksum").Do()
+	f, err := self.service.Files.Get(args.Id).SupportsAllDrives(true).Fields(downloadFileFields...).Do()
 	if err != nil {
 		return fmt.Errorf("Failed to get file: %s", err)
 	}
 
+	if isShortcut(f) {
+		if !args.FollowShortcuts {
+			return fmt.Errorf("'%s' is a shortcut to %s, use --follow-shortcuts to download the target", f.Name, shortcutTargetId(f))
+		}
+
+		f, err = self.shortcutTarget(f, downloadFileFields...)
+		if err != nil {
+			return err
+		}
+	}
+
 	if isDir(f) {
 		return fmt.Errorf("'%s' is a directory, use --recursive to download directories", f.Name)
 	}
@@ -42,13 +63,20 @@ func (self *Drive) Download(args DownloadArgs) error {
 		return fmt.Errorf("'%s' is a google document and must be exported, see the export command", f.Name)
 	}
 
-	bytes, rate, err := self.downloadBinary(f, args)
-	if err != nil {
-		return err
-	}
+	if args.Extract {
+		err = self.extractArchive(f, args)
+		if err != nil {
+			return err
+		}
+	} else {
+		bytes, rate, err := self.downloadBinary(f, args)
+		if err != nil {
+			return err
+		}
 
-	if !args.Stdout {
-		fmt.Fprintf(args.Out, "Downloaded %s at %s/s, total %s\n", f.Id, formatSize(rate, false), formatSize(bytes, false))
+		if !args.Stdout {
+			fmt.Fprintf(args.Out, "Downloaded %s at %s/s, total %s\n", f.Id, formatSize(rate, false), formatSize(bytes, false))
+		}
 	}
 
 	if args.Delete {
@@ -72,12 +100,13 @@ type DownloadQueryArgs struct {
 	Force     bool
 	Skip      bool
 	Recursive bool
+	Cipher    *Cipher
 }
 
 func (self *Drive) DownloadQuery(args DownloadQueryArgs) error {
 	listArgs := listAllFilesArgs{
 		query:  args.Query,
-		fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType,size,md5Checksum)"},
+		fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType,size,md5Checksum,appProperties)"},
 	}
 	files, err := self.listAllFiles(listArgs)
 	if err != nil {
@@ -90,6 +119,7 @@ func (self *Drive) DownloadQuery(args DownloadQueryArgs) error {
 		Path:     args.Path,
 		Force:    args.Force,
 		Skip:     args.Skip,
+		Cipher:   args.Cipher,
 	}
 
 	for _, f := range files {
@@ -108,11 +138,23 @@ func (self *Drive) DownloadQuery(args DownloadQueryArgs) error {
 }
 
 func (self *Drive) downloadRecursive(args DownloadArgs) error {
-	f, err := self.service.Files.Get(args.Id).Fields("id", "name", "size", "mimeType", "md5Checksum").Do()
+	f, err := self.service.Files.Get(args.Id).SupportsAllDrives(true).Fields(downloadFileFields...).Do()
 	if err != nil {
 		return fmt.Errorf("Failed to get file: %s", err)
 	}
 
+	if isShortcut(f) {
+		if !args.FollowShortcuts {
+			fmt.Fprintf(args.Out, "Skipping shortcut %s -> %s, use --follow-shortcuts to download the target\n", f.Name, shortcutTargetId(f))
+			return nil
+		}
+
+		f, err = self.shortcutTarget(f, downloadFileFields...)
+		if err != nil {
+			return err
+		}
+	}
+
 	if isDir(f) {
 		return self.downloadDirectory(f, args)
 	} else if isBinary(f) {
@@ -124,31 +166,30 @@ func (self *Drive) downloadRecursive(args DownloadArgs) error {
 }
 
 func (self *Drive) downloadBinary(f *drive.File, args DownloadArgs) (int64, int64, error) {
-	// Get timeout reader wrapper and context
-	timeoutReaderWrapper, ctx := getTimeoutReaderWrapperContext(args.Timeout)
+	name, err := plainName(f, args.Cipher)
+	if err != nil {
+		return 0, 0, err
+	}
 
-	res, err := self.service.Files.Get(f.Id).Context(ctx).Download()
+	res, body, err := self.getContent(f, args)
 	if err != nil {
-		if isTimeoutError(err) {
-			return 0, 0, fmt.Errorf("Failed to download file: timeout, no data was transferred for %v", args.Timeout)
-		}
-		return 0, 0, fmt.Errorf("Failed to download file: %s", err)
+		return 0, 0, err
 	}
 
 	// Close body on function exit
 	defer res.Body.Close()
 
 	// Path to file
-	fpath := filepath.Join(args.Path, f.Name)
+	fpath := filepath.Join(args.Path, name)
 
 	if !args.Stdout {
-		fmt.Fprintf(args.Out, "Downloading %s -> %s\n", f.Name, fpath)
+		fmt.Fprintf(args.Out, "Downloading %s -> %s\n", name, fpath)
 	}
 
 	return self.saveFile(saveFileArgs{
 		out:           args.Out,
-		body:          timeoutReaderWrapper(res.Body),
-		contentLength: res.ContentLength,
+		body:          body,
+		contentLength: contentLength(f, res),
 		fpath:         fpath,
 		force:         args.Force,
 		skip:          args.Skip,
@@ -157,6 +198,47 @@ func (self *Drive) downloadBinary(f *drive.File, args DownloadArgs) (int64, int6
 	})
 }
 
+// Starts the download of the file content, the returned reader
+// decrypts the content if the file is encrypted
+func (self *Drive) getContent(f *drive.File, args DownloadArgs) (*http.Response, io.Reader, error) {
+	if isEncrypted(f) && args.Cipher == nil {
+		return nil, nil, fmt.Errorf("'%s' is encrypted, use --key-file to decrypt it", f.Id)
+	}
+
+	// Get timeout reader wrapper and context
+	timeoutReaderWrapper, ctx := getTimeoutReaderWrapperContext(args.Timeout)
+
+	res, err := self.service.Files.Get(f.Id).SupportsAllDrives(true).Context(ctx).Download()
+	if err != nil {
+		if isTimeoutError(err) {
+			return nil, nil, fmt.Errorf("Failed to download file: timeout, no data was transferred for %v", args.Timeout)
+		}
+		return nil, nil, fmt.Errorf("Failed to download file: %s", err)
+	}
+
+	body := timeoutReaderWrapper(res.Body)
+
+	// Decrypt content
+	if isEncrypted(f) {
+		body, err = args.Cipher.DecryptReader(body)
+		if err != nil {
+			res.Body.Close()
+			return nil, nil, err
+		}
+	}
+
+	return res, body, nil
+}
+
+// Returns the size of the content read from getContent,
+// encrypted files are larger on drive than when decrypted
+func contentLength(f *drive.File, res *http.Response) int64 {
+	if isEncrypted(f) {
+		return plainSize(f.Size)
+	}
+	return res.ContentLength
+}
+
 type saveFileArgs struct {
 	out           io.Writer
 	body          io.Reader
@@ -224,6 +306,18 @@ func (self *Drive) saveFile(args saveFileArgs) (int64, int64, error) {
 }
 
 func (self *Drive) downloadDirectory(parent *drive.File, args DownloadArgs) error {
+	if args.ancestors == nil {
+		args.ancestors = map[string]bool{}
+	}
+
+	if args.ancestors[parent.Id] {
+		fmt.Fprintf(args.Out, "Skipping %s, a shortcut to it creates a cycle\n", parent.Name)
+		return nil
+	}
+
+	args.ancestors[parent.Id] = true
+	defer delete(args.ancestors, parent.Id)
+
 	listArgs := listAllFilesArgs{
 		query:  fmt.Sprintf("'%s' in parents", parent.Id),
 		fields: []googleapi.Field{"nextPageToken", "files(id,name)"},
@@ -233,7 +327,12 @@ func (self *Drive) downloadDirectory(parent *drive.File, args DownloadArgs) erro
 		return fmt.Errorf("Failed listing files: %s", err)
 	}
 
-	newPath := filepath.Join(args.Path, parent.Name)
+	name, err := plainName(parent, args.Cipher)
+	if err != nil {
+		return err
+	}
+
+	newPath := filepath.Join(args.Path, name)
 
 	for _, f := range files {
 		// Copy args and update changed fields
@@ -7,6 +7,12 @@ import (
 
 type Drive struct {
 	service *drive.Service
+
+	// Used for requests that are not supported by the service, i.e. range requests
+	client *http.Client
+
+	// Id of the shared drive to use instead of my drive, if any
+	sharedDriveId string
 }
 
 func New(client *http.Client) (*Drive, error) {
@@ -15,5 +21,5 @@ func New(client *http.Client) (*Drive, error) {
 		return nil, err
 	}
 
-	return &Drive{service}, nil
+	return &Drive{service: service, client: client}, nil
 }
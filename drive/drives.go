@@ -0,0 +1,234 @@
+package drive
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"text/tabwriter"
+)
+
+// Makes all commands operate on the given shared drive instead of my drive,
+// the shared drive is given by id or name
+func (self *Drive) UseSharedDrive(idOrName string) error {
+	id, err := self.resolveSharedDriveId(idOrName, false)
+	if err != nil {
+		return err
+	}
+
+	self.sharedDriveId = id
+	return nil
+}
+
+// Admin resolves the shared drive as a domain administrator
+func (self *Drive) resolveSharedDriveId(idOrName string, admin bool) (string, error) {
+	d, err := self.service.Drives.Get(idOrName).UseDomainAdminAccess(admin).Fields("id").Do()
+	if err == nil {
+		return d.Id, nil
+	}
+
+	var matches []*drive.Drive
+
+	query := fmt.Sprintf("name = '%s'", escapeQuery(idOrName))
+	err = self.service.Drives.List().Q(query).UseDomainAdminAccess(admin).PageSize(100).Fields("nextPageToken", "drives(id,name)").Pages(context.TODO(), func(dl *drive.DriveList) error {
+		matches = append(matches, dl.Drives...)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed to find shared drive '%s': %s", idOrName, err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("Found no shared drive with id or name '%s'", idOrName)
+	case 1:
+		return matches[0].Id, nil
+	}
+
+	return "", fmt.Errorf("Found %d shared drives named '%s', use the id instead", len(matches), idOrName)
+}
+
+// Returns the id of the root directory, which is
+// the shared drive itself when a shared drive is used
+func (self *Drive) rootId() string {
+	if self.sharedDriveId != "" {
+		return self.sharedDriveId
+	}
+	return "root"
+}
+
+// Files created without parents are placed in the root of the shared drive
+// when a shared drive is used, otherwise drive places them in the root of my drive
+func (self *Drive) defaultParents(parents []string) []string {
+	if len(parents) == 0 && self.sharedDriveId != "" {
+		return []string{self.sharedDriveId}
+	}
+	return parents
+}
+
+// Returns a list call that includes files in shared drives,
+// restricted to the selected shared drive if any
+func (self *Drive) filesList() *drive.FilesListCall {
+	call := self.service.Files.List().SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+	if self.sharedDriveId != "" {
+		call = call.Corpora("drive").DriveId(self.sharedDriveId)
+	}
+	return call
+}
+
+type ListDrivesArgs struct {
+	Out        io.Writer
+	SkipHeader bool
+	Admin      bool
+}
+
+func (self *Drive) ListDrives(args ListDrivesArgs) error {
+	var drives []*drive.Drive
+
+	err := self.service.Drives.List().UseDomainAdminAccess(args.Admin).PageSize(100).Fields("nextPageToken", "drives(id,name,createdTime,hidden)").Pages(context.TODO(), func(dl *drive.DriveList) error {
+		drives = append(drives, dl.Drives...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to list shared drives: %s", err)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(args.Out, 0, 0, 3, ' ', 0)
+
+	if !args.SkipHeader {
+		fmt.Fprintln(w, "Id\tName\tHidden\tCreated")
+	}
+
+	for _, d := range drives {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Id, d.Name, formatBool(d.Hidden), formatDatetime(d.CreatedTime))
+	}
+
+	w.Flush()
+	return nil
+}
+
+type CreateDriveArgs struct {
+	Out  io.Writer
+	Name string
+}
+
+func (self *Drive) CreateDrive(args CreateDriveArgs) error {
+	requestId, err := newRequestId()
+	if err != nil {
+		return err
+	}
+
+	d, err := self.createDrive(requestId, args.Name, 0)
+	if err != nil {
+		return fmt.Errorf("Failed to create shared drive: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Shared drive %s created\n", d.Id)
+	return nil
+}
+
+// Retries are made with the same request id, so a drive
+// that was created before the error is not created again
+func (self *Drive) createDrive(requestId, name string, try int) (*drive.Drive, error) {
+	d, err := self.service.Drives.Create(requestId, &drive.Drive{Name: name}).Fields("id", "name").Do()
+	if err != nil {
+		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
+			exponentialBackoffSleep(try)
+			try++
+			return self.createDrive(requestId, name, try)
+		}
+		return nil, err
+	}
+
+	return d, nil
+}
+
+type RenameDriveArgs struct {
+	Out   io.Writer
+	Id    string
+	Name  string
+	Admin bool
+}
+
+func (self *Drive) RenameDrive(args RenameDriveArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.service.Drives.Update(id, &drive.Drive{Name: args.Name}).UseDomainAdminAccess(args.Admin).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to rename shared drive: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Renamed shared drive %s to '%s'\n", id, args.Name)
+	return nil
+}
+
+type HideDriveArgs struct {
+	Out    io.Writer
+	Id     string
+	Unhide bool
+}
+
+// Hidden shared drives are not listed in the default view of the user
+func (self *Drive) HideDrive(args HideDriveArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, false)
+	if err != nil {
+		return err
+	}
+
+	if args.Unhide {
+		_, err = self.service.Drives.Unhide(id).Do()
+		if err != nil {
+			return fmt.Errorf("Failed to unhide shared drive: %s", err)
+		}
+
+		fmt.Fprintf(args.Out, "Shared drive %s is visible\n", id)
+		return nil
+	}
+
+	_, err = self.service.Drives.Hide(id).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to hide shared drive: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Shared drive %s is hidden\n", id)
+	return nil
+}
+
+type DeleteDriveArgs struct {
+	Out   io.Writer
+	Id    string
+	Admin bool
+}
+
+func (self *Drive) DeleteDrive(args DeleteDriveArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	err = self.service.Drives.Delete(id).UseDomainAdminAccess(args.Admin).Do()
+	if isResourceWithChildrenError(err) {
+		return fmt.Errorf("Failed to delete shared drive, it must be empty: %s", err)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to delete shared drive: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Deleted shared drive %s\n", id)
+	return nil
+}
+
+// Returns a random id that identifies a shared drive creation across retries
+func newRequestId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed to generate request id: %s", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,132 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+)
+
+type ListDriveMembersArgs struct {
+	Out   io.Writer
+	Id    string
+	Admin bool
+}
+
+func (self *Drive) ListDriveMembers(args ListDriveMembersArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := self.listAllPermissions(id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	printPermissions(printPermissionsArgs{
+		out:         args.Out,
+		permissions: permissions,
+	})
+	return nil
+}
+
+type AddDriveMemberArgs struct {
+	Out   io.Writer
+	Id    string
+	Type  string
+	Email string
+	Role  string
+	Admin bool
+}
+
+func (self *Drive) AddDriveMember(args AddDriveMemberArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	permission := &drive.Permission{
+		Role: args.Role,
+		Type: args.Type,
+	}
+
+	if args.Type == "domain" {
+		permission.Domain = args.Email
+	} else {
+		permission.EmailAddress = args.Email
+	}
+
+	_, err = self.service.Permissions.Create(id, permission).SupportsAllDrives(true).UseDomainAdminAccess(args.Admin).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to add member: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Granted %s permission to %s\n", args.Role, args.Email)
+	return nil
+}
+
+type RemoveDriveMemberArgs struct {
+	Out    io.Writer
+	Id     string
+	Member string
+	Admin  bool
+}
+
+// The member is given by permission id or email address
+func (self *Drive) RemoveDriveMember(args RemoveDriveMemberArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	p, err := self.findPermission(id, args.Member, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	err = self.service.Permissions.Delete(id, p.Id).SupportsAllDrives(true).UseDomainAdminAccess(args.Admin).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to remove member: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Permission revoked\n")
+	return nil
+}
+
+type SetDriveMemberRoleArgs struct {
+	Out    io.Writer
+	Id     string
+	Member string
+	Role   string
+	Admin  bool
+}
+
+func (self *Drive) SetDriveMemberRole(args SetDriveMemberRoleArgs) error {
+	id, err := self.resolveSharedDriveId(args.Id, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	p, err := self.findPermission(id, args.Member, args.Admin)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.service.Permissions.Update(id, p.Id, &drive.Permission{Role: args.Role}).SupportsAllDrives(true).UseDomainAdminAccess(args.Admin).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to update role: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Changed role of %s from %s to %s\n", memberName(p), p.Role, args.Role)
+	return nil
+}
+
+func memberName(p *drive.Permission) string {
+	if p.EmailAddress != "" {
+		return p.EmailAddress
+	}
+	if p.Domain != "" {
+		return p.Domain
+	}
+	return p.Id
+}
@@ -0,0 +1,198 @@
+package drive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+type DuArgs struct {
+	Out         io.Writer
+	Id          string
+	Depth       int
+	Top         int
+	SortOrder   string
+	Format      string
+	SizeInBytes bool
+	SkipHeader  bool
+	Parallel    int
+}
+
+func (self *Drive) Du(args DuArgs) error {
+	if err := checkOutputFormat(args.Format); err != nil {
+		return err
+	}
+
+	if err := checkDuSortOrder(args.SortOrder); err != nil {
+		return err
+	}
+
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	root, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	if !isDir(root) {
+		return fmt.Errorf("'%s' is not a directory", root.Name)
+	}
+
+	tree, err := self.fetchTree(root, 0, args.Parallel)
+	if err != nil {
+		return err
+	}
+
+	// Sort by name so files with multiple parents are always
+	// counted in the same directory
+	tree.sort("name")
+
+	var entries []*DuEntry
+	collectDu(tree, root.Name, 0, map[string]bool{root.Id: true}, args.Depth, &entries)
+
+	sortDuEntries(entries, args.SortOrder)
+
+	if args.Top > 0 && args.Top < len(entries) {
+		entries = entries[:args.Top]
+	}
+
+	switch args.Format {
+	case FormatCsv:
+		return printDuCsv(args.Out, entries, args.SkipHeader)
+	case FormatJson:
+		return printJson(args.Out, entries)
+	}
+
+	printDuTable(args.Out, entries, args)
+	return nil
+}
+
+func checkDuSortOrder(order string) error {
+	switch order {
+	case "size", "files", "docs", "path":
+		return nil
+	}
+	return fmt.Errorf("Invalid sort order '%s', must be size, files, docs or path", order)
+}
+
+// Disk usage of a directory and everything below it
+type DuEntry struct {
+	Id    string `json:"id"`
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+	Docs  int    `json:"docs"`
+}
+
+// Calculates the usage of the directory and adds an entry for each directory
+// down to the max depth. Files and directories already seen are skipped,
+// so files with multiple parents are only counted once
+func collectDu(node *treeNode, path string, depth int, seen map[string]bool, maxDepth int, entries *[]*DuEntry) *DuEntry {
+	entry := &DuEntry{
+		Id:    node.file.Id,
+		Path:  path,
+		Depth: depth,
+	}
+
+	if maxDepth == 0 || depth <= maxDepth {
+		*entries = append(*entries, entry)
+	}
+
+	for _, child := range node.children {
+		if seen[child.file.Id] {
+			continue
+		}
+		seen[child.file.Id] = true
+
+		switch {
+		case isDir(child.file):
+			sub := collectDu(child, filepath.Join(path, child.file.Name), depth+1, seen, maxDepth, entries)
+			entry.Size += sub.Size
+			entry.Files += sub.Files
+			entry.Docs += sub.Docs
+		case isBinary(child.file):
+			entry.Size += child.file.Size
+			entry.Files++
+		default:
+			// Native documents does not use any quota
+			entry.Docs++
+		}
+	}
+
+	return entry
+}
+
+func sortDuEntries(entries []*DuEntry, order string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		switch order {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case "files":
+			if a.Files != b.Files {
+				return a.Files > b.Files
+			}
+		case "docs":
+			if a.Docs != b.Docs {
+				return a.Docs > b.Docs
+			}
+		}
+
+		return a.Path < b.Path
+	})
+}
+
+func printDuTable(out io.Writer, entries []*DuEntry, args DuArgs) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	if !args.SkipHeader {
+		fmt.Fprintln(w, "Id\tPath\tSize\tFiles\tDocs")
+	}
+
+	for _, e := range entries {
+		size := formatSize(e.Size, args.SizeInBytes)
+		if size == "" {
+			size = "0 B"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", e.Id, e.Path, size, e.Files, e.Docs)
+	}
+
+	w.Flush()
+}
+
+func printDuCsv(out io.Writer, entries []*DuEntry, skipHeader bool) error {
+	w := csv.NewWriter(out)
+
+	if !skipHeader {
+		w.Write([]string{"id", "path", "depth", "size", "files", "docs"})
+	}
+
+	for _, e := range entries {
+		w.Write([]string{
+			e.Id,
+			e.Path,
+			strconv.Itoa(e.Depth),
+			strconv.FormatInt(e.Size, 10),
+			strconv.Itoa(e.Files),
+			strconv.Itoa(e.Docs),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Failed to write csv: %s", err)
+	}
+	return nil
+}
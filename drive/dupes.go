@@ -0,0 +1,268 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+const (
+	KeepOldest       = "oldest"
+	KeepNewest       = "newest"
+	KeepShortestPath = "shortest-path"
+)
+
+type DupesArgs struct {
+	Out         io.Writer
+	ParentId    string
+	Recursive   bool
+	SizeInBytes bool
+}
+
+func (self *Drive) Dupes(args DupesArgs) error {
+	groups, err := self.findDupes(args.ParentId, args.Recursive)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintln(args.Out, "No duplicate files found")
+		return nil
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(args.Out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Md5\tSize\tId\tPath\tCreated")
+
+	var count int
+	var wasted int64
+
+	for _, g := range groups {
+		for _, f := range g.files {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g.md5, formatSize(g.size, args.SizeInBytes), f.Id, g.paths[f.Id], formatDatetime(f.CreatedTime))
+		}
+		count += len(g.files) - 1
+		wasted += g.wasted()
+	}
+
+	w.Flush()
+
+	fmt.Fprintf(args.Out, "\nFound %d duplicates of %d files, wasting %s\n", count, len(groups), formatSize(wasted, args.SizeInBytes))
+	return nil
+}
+
+type ResolveDupesArgs struct {
+	Out         io.Writer
+	ParentId    string
+	Recursive   bool
+	Keep        string
+	Shortcut    bool
+	DryRun      bool
+	SizeInBytes bool
+}
+
+func (self *Drive) ResolveDupes(args ResolveDupesArgs) error {
+	switch args.Keep {
+	case KeepOldest, KeepNewest, KeepShortestPath:
+	default:
+		return fmt.Errorf("Invalid keep strategy '%s', must be %s, %s or %s", args.Keep, KeepOldest, KeepNewest, KeepShortestPath)
+	}
+
+	groups, err := self.findDupes(args.ParentId, args.Recursive)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintln(args.Out, "No duplicate files found")
+		return nil
+	}
+
+	action := "Trash"
+	if args.Shortcut {
+		action = "Replace with shortcut"
+	}
+
+	var removed, skipped int
+	var freed int64
+
+	for _, g := range groups {
+		keep := g.keeper(args.Keep)
+		fmt.Fprintf(args.Out, "Keeping %s (%s)\n", g.paths[keep.Id], keep.Id)
+
+		for _, f := range g.files {
+			if f.Id == keep.Id {
+				continue
+			}
+
+			// Removing a file from a sync directory would make the next sync upload it again
+			if _, ok := f.AppProperties["sync"]; ok {
+				fmt.Fprintf(args.Out, "  Skipping %s (%s), the file is part of a sync directory\n", g.paths[f.Id], f.Id)
+				skipped++
+				continue
+			}
+
+			fmt.Fprintf(args.Out, "  %s %s (%s)\n", action, g.paths[f.Id], f.Id)
+
+			if !args.DryRun {
+				err = self.removeDupe(f, keep, args.Shortcut)
+				if err != nil {
+					return err
+				}
+			}
+
+			removed++
+			freed += g.size
+		}
+	}
+
+	if args.DryRun {
+		fmt.Fprintf(args.Out, "Would remove %d duplicates, freeing %s. %d skipped\n", removed, formatSize(freed, args.SizeInBytes), skipped)
+		return nil
+	}
+
+	fmt.Fprintf(args.Out, "Removed %d duplicates, freeing %s. %d skipped\n", removed, formatSize(freed, args.SizeInBytes), skipped)
+	return nil
+}
+
+// The shortcut is created before the file is trashed,
+// so nothing is lost if creating the shortcut fails
+func (self *Drive) removeDupe(f, keep *drive.File, shortcut bool) error {
+	if shortcut {
+		_, err := self.createShortcut(f.Name, keep.Id, f.Parents)
+		if err != nil {
+			return err
+		}
+	}
+
+	return self.trashFile(f.Id)
+}
+
+// Files with the same md5 and size
+type dupeGroup struct {
+	md5   string
+	size  int64
+	files []*drive.File
+	paths map[string]string
+}
+
+func (self *dupeGroup) wasted() int64 {
+	return self.size * int64(len(self.files)-1)
+}
+
+func (self *dupeGroup) keeper(strategy string) *drive.File {
+	files := make([]*drive.File, len(self.files))
+	copy(files, self.files)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+
+		switch strategy {
+		case KeepNewest:
+			return a.CreatedTime > b.CreatedTime
+		case KeepShortestPath:
+			if len(self.paths[a.Id]) != len(self.paths[b.Id]) {
+				return len(self.paths[a.Id]) < len(self.paths[b.Id])
+			}
+		}
+
+		return a.CreatedTime < b.CreatedTime
+	})
+
+	return files[0]
+}
+
+// Finds binary files with the same content that are owned by me, or are
+// in the shared drive. The groups are sorted by wasted space
+func (self *Drive) findDupes(parentId string, recursive bool) ([]*dupeGroup, error) {
+	query := &findQuery{
+		terms: []string{
+			"trashed = false",
+			"not mimeType contains 'application/vnd.google-apps.'",
+		},
+		// Empty files does not waste any space
+		filter: func(f *drive.File) bool {
+			return isBinary(f) && f.Size > 0
+		},
+	}
+
+	// Files in shared drives are owned by the shared drive
+	if self.sharedDriveId == "" {
+		query.terms = append(query.terms, "'me' in owners")
+	}
+
+	var parentIds []string
+	if parentId != "" {
+		id, err := self.resolveId(parentId)
+		if err != nil {
+			return nil, err
+		}
+
+		parentIds = []string{id}
+		if recursive {
+			parentIds, err = self.listFolderIds(id)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	files, err := self.findFiles(query, parentIds)
+	if err != nil {
+		return nil, err
+	}
+
+	groupMap := map[string]*dupeGroup{}
+	seen := map[string]bool{}
+
+	for _, f := range files {
+		// Files with multiple parents may be listed more than once
+		if seen[f.Id] {
+			continue
+		}
+		seen[f.Id] = true
+
+		key := fmt.Sprintf("%s:%d", f.Md5Checksum, f.Size)
+		g, ok := groupMap[key]
+		if !ok {
+			g = &dupeGroup{md5: f.Md5Checksum, size: f.Size, paths: map[string]string{}}
+			groupMap[key] = g
+		}
+		g.files = append(g.files, f)
+	}
+
+	var groups []*dupeGroup
+	pathfinder := self.newPathfinder()
+
+	for _, g := range groupMap {
+		if len(g.files) < 2 {
+			continue
+		}
+
+		for _, f := range g.files {
+			path, err := pathfinder.absPath(f)
+			if err != nil {
+				return nil, err
+			}
+			g.paths[f.Id] = path
+		}
+
+		sort.Slice(g.files, func(i, j int) bool {
+			return g.paths[g.files[i].Id] < g.paths[g.files[j].Id]
+		})
+
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].wasted() != groups[j].wasted() {
+			return groups[i].wasted() > groups[j].wasted()
+		}
+		return groups[i].md5 < groups[j].md5
+	})
+
+	return groups, nil
+}
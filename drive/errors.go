@@ -30,6 +30,36 @@ func isRateLimitError(err error) bool {
 	return ok && ae.Code == 403
 }
 
+// Returns true if the request was denied because the
+// user lacks the required role on the file or shared drive
+func isInsufficientPermissionsError(err error) bool {
+	ae, ok := err.(*googleapi.Error)
+	if !ok || ae.Code != 403 {
+		return false
+	}
+
+	for _, e := range ae.Errors {
+		if e.Reason == "insufficientPermissions" || e.Reason == "insufficientFilePermissions" {
+			return true
+		}
+	}
+	return false
+}
+
+func isResourceWithChildrenError(err error) bool {
+	ae, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+
+	for _, e := range ae.Errors {
+		if e.Reason == "cannotDeleteResourceWithChildren" {
+			return true
+		}
+	}
+	return false
+}
+
 func isTimeoutError(err error) bool {
 	return err == context.Canceled
 }
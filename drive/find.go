@@ -0,0 +1,415 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Max number of parents in a single query when searching recursively
+const maxParentsPerQuery = 50
+
+type FindArgs struct {
+	Out            io.Writer
+	Progress       io.Writer
+	Name           string
+	Mime           string
+	Type           string
+	Larger         string
+	ModifiedAfter  string
+	ModifiedBefore string
+	Owner          string
+	Shared         bool
+	Starred        bool
+	Properties     map[string]string
+	ParentId       string
+	Recursive      bool
+	Exec           string
+	Delete         bool
+	Download       bool
+	Path           string
+	Force          bool
+	Skip           bool
+	Cipher         *Cipher
+	SkipHeader     bool
+	SizeInBytes    bool
+}
+
+func (self *Drive) Find(args FindArgs) error {
+	// Without any filters every file would be moved to trash
+	if args.Delete && !hasFindFilters(args) {
+		return fmt.Errorf("At least one filter is required with --delete")
+	}
+
+	query, err := newFindQuery(args)
+	if err != nil {
+		return err
+	}
+
+	var parentIds []string
+	if args.ParentId != "" {
+		parentId, err := self.resolveId(args.ParentId)
+		if err != nil {
+			return err
+		}
+
+		parentIds = []string{parentId}
+		if args.Recursive {
+			parentIds, err = self.listFolderIds(parentId)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	files, err := self.findFiles(query, parentIds)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args.Exec != "":
+		return findExec(files, args)
+	case args.Delete:
+		return self.findDelete(files, args)
+	case args.Download:
+		return self.findDownload(files, args)
+	}
+
+	PrintFileList(PrintFileListArgs{
+		Out:         args.Out,
+		Files:       files,
+		SkipHeader:  args.SkipHeader,
+		SizeInBytes: args.SizeInBytes,
+	})
+	return nil
+}
+
+func hasFindFilters(args FindArgs) bool {
+	return args.Name != "" ||
+		args.Mime != "" ||
+		args.Type != "" ||
+		args.Larger != "" ||
+		args.ModifiedAfter != "" ||
+		args.ModifiedBefore != "" ||
+		args.Owner != "" ||
+		args.Shared ||
+		args.Starred ||
+		len(args.Properties) > 0 ||
+		args.ParentId != ""
+}
+
+// A find query consists of the terms that can be expressed as a drive query,
+// and a filter for the conditions that drive is unable to search for
+type findQuery struct {
+	terms  []string
+	filter func(*drive.File) bool
+}
+
+func newFindQuery(args FindArgs) (*findQuery, error) {
+	terms := []string{"trashed = false"}
+	var filters []func(*drive.File) bool
+
+	if args.Name != "" {
+		if _, err := path.Match(args.Name, ""); err != nil {
+			return nil, fmt.Errorf("Invalid name pattern '%s': %s", args.Name, err)
+		}
+
+		// Drive can only search for exact names or name prefixes,
+		// the rest of the pattern is matched after listing
+		prefix, isGlob := globPrefix(args.Name)
+		if !isGlob {
+			terms = append(terms, fmt.Sprintf("name = '%s'", escapeQuery(args.Name)))
+		} else if prefix != "" {
+			terms = append(terms, fmt.Sprintf("name contains '%s'", escapeQuery(prefix)))
+		}
+
+		pattern := strings.ToLower(args.Name)
+		filters = append(filters, func(f *drive.File) bool {
+			match, _ := path.Match(pattern, strings.ToLower(f.Name))
+			return match
+		})
+	}
+
+	if args.Mime != "" {
+		if strings.HasSuffix(args.Mime, "/*") {
+			terms = append(terms, fmt.Sprintf("mimeType contains '%s'", escapeQuery(strings.TrimSuffix(args.Mime, "*"))))
+		} else {
+			terms = append(terms, fmt.Sprintf("mimeType = '%s'", escapeQuery(args.Mime)))
+		}
+	}
+
+	switch args.Type {
+	case "":
+	case "dir":
+		terms = append(terms, fmt.Sprintf("mimeType = '%s'", DirectoryMimeType))
+	case "doc":
+		terms = append(terms, fmt.Sprintf("mimeType contains 'application/vnd.google-apps.' and mimeType != '%s'", DirectoryMimeType))
+	case "bin":
+		terms = append(terms, "not mimeType contains 'application/vnd.google-apps.'")
+		filters = append(filters, isBinary)
+	default:
+		return nil, fmt.Errorf("Invalid type '%s', must be dir, doc or bin", args.Type)
+	}
+
+	if args.Larger != "" {
+		size, err := parseSize(args.Larger)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, func(f *drive.File) bool {
+			return f.Size > size
+		})
+	}
+
+	if args.ModifiedAfter != "" {
+		t, err := parseDatetime(args.ModifiedAfter)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fmt.Sprintf("modifiedTime > '%s'", t.Format(time.RFC3339)))
+	}
+
+	if args.ModifiedBefore != "" {
+		t, err := parseDatetime(args.ModifiedBefore)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fmt.Sprintf("modifiedTime < '%s'", t.Format(time.RFC3339)))
+	}
+
+	if args.Owner != "" {
+		terms = append(terms, fmt.Sprintf("'%s' in owners", escapeQuery(args.Owner)))
+	}
+
+	if args.Starred {
+		terms = append(terms, "starred = true")
+	}
+
+	// Sorted to make the query deterministic
+	keys := make([]string, 0, len(args.Properties))
+	for key := range args.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		terms = append(terms, fmt.Sprintf("properties has { key='%s' and value='%s' }", escapeQuery(key), escapeQuery(args.Properties[key])))
+	}
+
+	if args.Shared {
+		filters = append(filters, func(f *drive.File) bool {
+			return f.Shared
+		})
+	}
+
+	return &findQuery{
+		terms: terms,
+		filter: func(f *drive.File) bool {
+			for _, fn := range filters {
+				if !fn(f) {
+					return false
+				}
+			}
+			return true
+		},
+	}, nil
+}
+
+// Returns the query limited to the given parents
+func (self *findQuery) String(parentIds []string) string {
+	terms := self.terms
+
+	if len(parentIds) > 0 {
+		var parents []string
+		for _, id := range parentIds {
+			parents = append(parents, fmt.Sprintf("'%s' in parents", escapeQuery(id)))
+		}
+		terms = append(terms[:len(terms):len(terms)], "("+strings.Join(parents, " or ")+")")
+	}
+
+	return strings.Join(terms, " and ")
+}
+
+// Returns the part of the glob pattern before the first wildcard,
+// and whether the pattern contains any wildcards
+func globPrefix(pattern string) (string, bool) {
+	i := strings.IndexAny(pattern, `*?[\`)
+	if i == -1 {
+		return pattern, false
+	}
+	return pattern[:i], true
+}
+
+func (self *Drive) findFiles(query *findQuery, parentIds []string) ([]*drive.File, error) {
+	var files []*drive.File
+
+	// Search all of my drive when no parents are given
+	batches := [][]string{nil}
+	if len(parentIds) > 0 {
+		batches = batchIds(parentIds, maxParentsPerQuery)
+	}
+
+	for _, batch := range batches {
+		listArgs := listAllFilesArgs{
+			query:  query.String(batch),
+			fields: []googleapi.Field{"nextPageToken", "files(id,name,md5Checksum,mimeType,size,createdTime,modifiedTime,parents,shared,appProperties)"},
+		}
+		result, err := self.listAllFiles(listArgs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list files: %s", err)
+		}
+
+		for _, f := range result {
+			if query.filter(f) {
+				files = append(files, f)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// Returns the id of the folder and all folders below it
+func (self *Drive) listFolderIds(rootId string) ([]string, error) {
+	ids := []string{rootId}
+	level := []string{rootId}
+
+	// Folders with multiple parents may be found more than once
+	seen := map[string]bool{rootId: true}
+
+	for len(level) > 0 {
+		var next []string
+
+		for _, batch := range batchIds(level, maxParentsPerQuery) {
+			query := &findQuery{terms: []string{"trashed = false", fmt.Sprintf("mimeType = '%s'", DirectoryMimeType)}}
+			listArgs := listAllFilesArgs{
+				query:  query.String(batch),
+				fields: []googleapi.Field{"nextPageToken", "files(id)"},
+			}
+			folders, err := self.listAllFiles(listArgs)
+			if err != nil {
+				return nil, fmt.Errorf("Failed listing folders: %s", err)
+			}
+
+			for _, f := range folders {
+				if !seen[f.Id] {
+					seen[f.Id] = true
+					next = append(next, f.Id)
+				}
+			}
+		}
+
+		ids = append(ids, next...)
+		level = next
+	}
+
+	return ids, nil
+}
+
+func batchIds(ids []string, size int) [][]string {
+	var batches [][]string
+	for len(ids) > size {
+		batches = append(batches, ids[:size])
+		ids = ids[size:]
+	}
+	return append(batches, ids)
+}
+
+// Runs the command for each file, {} in the command is replaced with
+// the file id and {name} with the file name
+func findExec(files []*drive.File, args FindArgs) error {
+	fields := strings.Fields(args.Exec)
+	if len(fields) == 0 {
+		return fmt.Errorf("Empty command given to --exec")
+	}
+
+	for _, f := range files {
+		replacer := strings.NewReplacer("{}", f.Id, "{name}", f.Name)
+
+		cmdArgs := make([]string, len(fields))
+		for i, field := range fields {
+			cmdArgs[i] = replacer.Replace(field)
+		}
+
+		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = args.Out
+		cmd.Stderr = os.Stderr
+
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("Command failed for '%s' (%s): %s", f.Name, f.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// Moves the files to trash, the files are only listed unless force is given
+func (self *Drive) findDelete(files []*drive.File, args FindArgs) error {
+	var trashFiles []*drive.File
+
+	for _, f := range files {
+		// Removing a file from a sync directory would make the next sync upload it again
+		if _, ok := f.AppProperties["sync"]; ok {
+			fmt.Fprintf(args.Out, "Skipping '%s' (%s), the file is part of a sync directory\n", f.Name, f.Id)
+			continue
+		}
+		trashFiles = append(trashFiles, f)
+	}
+
+	if len(trashFiles) == 0 {
+		return nil
+	}
+
+	if !args.Force {
+		for _, f := range trashFiles {
+			fmt.Fprintf(args.Out, "Would move '%s' (%s) to trash\n", f.Name, f.Id)
+		}
+		return fmt.Errorf("%d files would be moved to trash, use --force to continue", len(trashFiles))
+	}
+
+	for i, f := range trashFiles {
+		err := self.trashFile(f.Id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(args.Out, "[%04d/%04d] Moved '%s' to trash\n", i+1, len(trashFiles), f.Name)
+	}
+
+	return nil
+}
+
+func (self *Drive) findDownload(files []*drive.File, args FindArgs) error {
+	downloadArgs := DownloadArgs{
+		Out:      args.Out,
+		Progress: args.Progress,
+		Path:     args.Path,
+		Force:    args.Force,
+		Skip:     args.Skip,
+		Cipher:   args.Cipher,
+	}
+
+	for _, f := range files {
+		if !isBinary(f) {
+			fmt.Fprintf(args.Out, "Skipping '%s', only binary files can be downloaded\n", f.Name)
+			continue
+		}
+
+		_, _, err := self.downloadBinary(f, downloadArgs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package drive
+
+import (
+	"google.golang.org/api/drive/v3"
+	"testing"
+)
+
+func TestGlobPrefix(t *testing.T) {
+	cases := []struct {
+		pattern string
+		prefix  string
+		isGlob  bool
+	}{
+		{"report.pdf", "report.pdf", false},
+		{"", "", false},
+		{"IMG_*", "IMG_", true},
+		{"*.jpg", "", true},
+		{"a?c", "a", true},
+		{"a[bc]", "a", true},
+		{`a\*`, "a", true},
+	}
+
+	for _, c := range cases {
+		prefix, isGlob := globPrefix(c.pattern)
+		if prefix != c.prefix || isGlob != c.isGlob {
+			t.Errorf("globPrefix(%q) = %q, %t, want %q, %t", c.pattern, prefix, isGlob, c.prefix, c.isGlob)
+		}
+	}
+}
+
+func TestNewFindQuery(t *testing.T) {
+	cases := []struct {
+		args FindArgs
+		want string
+	}{
+		{FindArgs{}, "trashed = false"},
+		{FindArgs{Name: "report.pdf"}, "trashed = false and name = 'report.pdf'"},
+		{FindArgs{Name: "IMG_*.jpg"}, "trashed = false and name contains 'IMG_'"},
+		{FindArgs{Name: "*.jpg"}, "trashed = false"},
+		{FindArgs{Name: "it's"}, `trashed = false and name = 'it\'s'`},
+		{FindArgs{Mime: "image/*"}, "trashed = false and mimeType contains 'image/'"},
+		{FindArgs{Mime: "image/png"}, "trashed = false and mimeType = 'image/png'"},
+		{FindArgs{Type: "dir"}, "trashed = false and mimeType = '" + DirectoryMimeType + "'"},
+		{FindArgs{Type: "bin"}, "trashed = false and not mimeType contains 'application/vnd.google-apps.'"},
+		{FindArgs{ModifiedAfter: "2016-01-02T15:04:05Z"}, "trashed = false and modifiedTime > '2016-01-02T15:04:05Z'"},
+		{FindArgs{ModifiedBefore: "2016-01-02T15:04:05Z"}, "trashed = false and modifiedTime < '2016-01-02T15:04:05Z'"},
+		{FindArgs{Owner: "me@example.com"}, "trashed = false and 'me@example.com' in owners"},
+		{FindArgs{Starred: true}, "trashed = false and starred = true"},
+		{
+			FindArgs{Properties: map[string]string{"b": "2", "a": "1"}},
+			"trashed = false and properties has { key='a' and value='1' } and properties has { key='b' and value='2' }",
+		},
+		{
+			FindArgs{Name: "a.txt", Starred: true},
+			"trashed = false and name = 'a.txt' and starred = true",
+		},
+	}
+
+	for _, c := range cases {
+		query, err := newFindQuery(c.args)
+		if err != nil {
+			t.Errorf("newFindQuery(%+v) failed: %s", c.args, err)
+			continue
+		}
+
+		if got := query.String(nil); got != c.want {
+			t.Errorf("newFindQuery(%+v) = %q, want %q", c.args, got, c.want)
+		}
+	}
+}
+
+func TestNewFindQueryErrors(t *testing.T) {
+	cases := []FindArgs{
+		{Name: "a[b"},
+		{Type: "image"},
+		{Larger: "10X"},
+		{ModifiedAfter: "yesterday"},
+		{ModifiedBefore: "2016-13-01"},
+	}
+
+	for _, args := range cases {
+		if _, err := newFindQuery(args); err == nil {
+			t.Errorf("newFindQuery(%+v) succeeded, want error", args)
+		}
+	}
+}
+
+func TestFindQueryFilter(t *testing.T) {
+	cases := []struct {
+		args FindArgs
+		file *drive.File
+		want bool
+	}{
+		{FindArgs{Name: "IMG_*.jpg"}, &drive.File{Name: "IMG_0001.jpg"}, true},
+		{FindArgs{Name: "IMG_*.jpg"}, &drive.File{Name: "img_0001.JPG"}, true},
+		{FindArgs{Name: "IMG_*.jpg"}, &drive.File{Name: "IMG_0001.png"}, false},
+		{FindArgs{Type: "bin"}, &drive.File{Name: "a", Md5Checksum: "abc"}, true},
+		{FindArgs{Type: "bin"}, &drive.File{Name: "a"}, false},
+		{FindArgs{Larger: "1K"}, &drive.File{Size: 1001}, true},
+		{FindArgs{Larger: "1K"}, &drive.File{Size: 1000}, false},
+		{FindArgs{Shared: true}, &drive.File{Shared: true}, true},
+		{FindArgs{Shared: true}, &drive.File{}, false},
+		{FindArgs{Name: "*.jpg", Larger: "1K"}, &drive.File{Name: "a.jpg", Size: 10}, false},
+	}
+
+	for _, c := range cases {
+		query, err := newFindQuery(c.args)
+		if err != nil {
+			t.Fatalf("newFindQuery(%+v) failed: %s", c.args, err)
+		}
+
+		if got := query.filter(c.file); got != c.want {
+			t.Errorf("newFindQuery(%+v) filter of %+v = %t, want %t", c.args, c.file, got, c.want)
+		}
+	}
+}
+
+func TestFindQueryString(t *testing.T) {
+	query := &findQuery{terms: []string{"trashed = false"}}
+
+	cases := []struct {
+		parentIds []string
+		want      string
+	}{
+		{nil, "trashed = false"},
+		{[]string{"a"}, "trashed = false and ('a' in parents)"},
+		{[]string{"a", "b"}, "trashed = false and ('a' in parents or 'b' in parents)"},
+		{[]string{"c"}, "trashed = false and ('c' in parents)"},
+	}
+
+	// The terms must not change between calls
+	for _, c := range cases {
+		if got := query.String(c.parentIds); got != c.want {
+			t.Errorf("findQuery.String(%q) = %q, want %q", c.parentIds, got, c.want)
+		}
+	}
+}
+
+func TestHasFindFilters(t *testing.T) {
+	cases := []struct {
+		args FindArgs
+		want bool
+	}{
+		{FindArgs{}, false},
+		{FindArgs{Delete: true, Force: true, Recursive: true}, false},
+		{FindArgs{Name: "*.tmp"}, true},
+		{FindArgs{Larger: "1G"}, true},
+		{FindArgs{Starred: true}, true},
+		{FindArgs{Properties: map[string]string{"a": "b"}}, true},
+		{FindArgs{ParentId: "id"}, true},
+	}
+
+	for _, c := range cases {
+		if got := hasFindFilters(c.args); got != c.want {
+			t.Errorf("hasFindFilters(%+v) = %t, want %t", c.args, got, c.want)
+		}
+	}
+}
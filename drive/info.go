@@ -13,7 +13,7 @@ type FileInfoArgs struct {
 }
 
 func (self *Drive) Info(args FileInfoArgs) error {
-	f, err := self.service.Files.Get(args.Id).Fields("id", "name", "size", "createdTime", "modifiedTime", "md5Checksum", "mimeType", "parents", "shared", "description", "webContentLink", "webViewLink").Do()
+	f, err := self.service.Files.Get(args.Id).SupportsAllDrives(true).Fields("id", "name", "size", "createdTime", "modifiedTime", "md5Checksum", "mimeType", "parents", "shared", "description", "webContentLink", "webViewLink", "shortcutDetails").Do()
 	if err != nil {
 		return fmt.Errorf("Failed to get file: %s", err)
 	}
@@ -44,6 +44,11 @@ type PrintFileInfoArgs struct {
 func PrintFileInfo(args PrintFileInfoArgs) {
 	f := args.File
 
+	targetMime := ""
+	if f.ShortcutDetails != nil {
+		targetMime = f.ShortcutDetails.TargetMimeType
+	}
+
 	items := []kv{
 		kv{"Id", f.Id},
 		kv{"Name", f.Name},
@@ -54,6 +59,8 @@ func PrintFileInfo(args PrintFileInfoArgs) {
 		kv{"Created", formatDatetime(f.CreatedTime)},
 		kv{"Modified", formatDatetime(f.ModifiedTime)},
 		kv{"Md5sum", f.Md5Checksum},
+		kv{"Target", shortcutTargetId(f)},
+		kv{"TargetMime", targetMime},
 		kv{"Shared", formatBool(f.Shared)},
 		kv{"Parents", formatList(f.Parents)},
 		kv{"ViewUrl", f.WebViewLink},
@@ -23,7 +23,7 @@ type ListFilesArgs struct {
 func (self *Drive) List(args ListFilesArgs) (err error) {
 	listArgs := listAllFilesArgs{
 		query:     args.Query,
-		fields:    []googleapi.Field{"nextPageToken", "files(id,name,md5Checksum,mimeType,size,createdTime,parents)"},
+		fields:    []googleapi.Field{"nextPageToken", "files(id,name,md5Checksum,mimeType,size,createdTime,parents,shortcutDetails)"},
 		sortOrder: args.SortOrder,
 		maxFiles:  args.MaxFiles,
 	}
@@ -74,7 +74,7 @@ func (self *Drive) listAllFiles(args listAllFilesArgs) ([]*drive.File, error) {
 
 	controlledStop := fmt.Errorf("Controlled stop")
 
-	err := self.service.Files.List().Q(args.query).Fields(args.fields...).OrderBy(args.sortOrder).PageSize(pageSize).Pages(context.TODO(), func(fl *drive.FileList) error {
+	err := self.filesList().Q(args.query).Fields(args.fields...).OrderBy(args.sortOrder).PageSize(pageSize).Pages(context.TODO(), func(fl *drive.FileList) error {
 		files = append(files, fl.Files...)
 
 		// Stop when we have all the files we need
@@ -114,9 +114,14 @@ func PrintFileList(args PrintFileListArgs) {
 	}
 
 	for _, f := range args.Files {
+		name := truncateString(f.Name, args.NameWidth)
+		if targetId := shortcutTargetId(f); targetId != "" {
+			name = fmt.Sprintf("%s -> %s", name, targetId)
+		}
+
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			f.Id,
-			truncateString(f.Name, args.NameWidth),
+			name,
 			filetype(f),
 			formatSize(f.Size, args.SizeInBytes),
 			formatDatetime(f.CreatedTime),
@@ -129,6 +134,8 @@ func PrintFileList(args PrintFileListArgs) {
 func filetype(f *drive.File) string {
 	if isDir(f) {
 		return "dir"
+	} else if isShortcut(f) {
+		return "shortcut"
 	} else if isBinary(f) {
 		return "bin"
 	}
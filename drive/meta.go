@@ -0,0 +1,185 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// App properties used by gdrive itself, changing them breaks sync and decryption
+var reservedAppProperties = map[string]bool{
+	"sync":                true,
+	"syncRoot":            true,
+	"syncRootId":          true,
+	EncryptedProperty:     true,
+	EncryptedNameProperty: true,
+	PlainChecksumProperty: true,
+}
+
+type GetMetaArgs struct {
+	Out        io.Writer
+	Id         string
+	App        bool
+	SkipHeader bool
+}
+
+func (self *Drive) GetMeta(args GetMetaArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "properties", "appProperties").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	properties := f.Properties
+	if args.App {
+		properties = f.AppProperties
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := new(tabwriter.Writer)
+	w.Init(args.Out, 0, 0, 3, ' ', 0)
+
+	if !args.SkipHeader {
+		fmt.Fprintln(w, "Key\tValue")
+	}
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, properties[key])
+	}
+
+	w.Flush()
+	return nil
+}
+
+type SetMetaArgs struct {
+	Out      io.Writer
+	Id       string
+	Property string
+	App      bool
+}
+
+func (self *Drive) SetMeta(args SetMetaArgs) error {
+	properties, err := ParseProperties([]string{args.Property})
+	if err != nil {
+		return err
+	}
+
+	dstFile := &drive.File{}
+	if args.App {
+		if err = checkAppProperties(properties); err != nil {
+			return err
+		}
+		dstFile.AppProperties = properties
+	} else {
+		dstFile.Properties = properties
+	}
+
+	return self.updateMeta(args.Out, args.Id, dstFile, "Set %s on %s\n", args.Property)
+}
+
+type UnsetMetaArgs struct {
+	Out io.Writer
+	Id  string
+	Key string
+	App bool
+}
+
+func (self *Drive) UnsetMeta(args UnsetMetaArgs) error {
+	dstFile := &drive.File{}
+	if args.App {
+		if err := checkAppProperties(map[string]string{args.Key: ""}); err != nil {
+			return err
+		}
+		dstFile.NullFields = []string{"AppProperties." + args.Key}
+	} else {
+		dstFile.NullFields = []string{"Properties." + args.Key}
+	}
+
+	return self.updateMeta(args.Out, args.Id, dstFile, "Removed %s from %s\n", args.Key)
+}
+
+type StarArgs struct {
+	Out     io.Writer
+	Ids     []string
+	Starred bool
+}
+
+func (self *Drive) Star(args StarArgs) error {
+	format := "Starred %s\n"
+	if !args.Starred {
+		format = "Unstarred %s\n"
+	}
+
+	for _, idOrPath := range args.Ids {
+		id, err := self.resolveId(idOrPath)
+		if err != nil {
+			return err
+		}
+
+		// Starred must be sent explicitly when false
+		dstFile := &drive.File{Starred: args.Starred, ForceSendFields: []string{"Starred"}}
+
+		_, err = self.service.Files.Update(id, dstFile).SupportsAllDrives(true).Fields("id").Do()
+		if err != nil {
+			return fmt.Errorf("Failed to update file: %s", err)
+		}
+
+		fmt.Fprintf(args.Out, format, id)
+	}
+
+	return nil
+}
+
+func (self *Drive) updateMeta(out io.Writer, idOrPath string, dstFile *drive.File, format, value string) error {
+	id, err := self.resolveId(idOrPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = self.service.Files.Update(id, dstFile).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to update properties: %s", err)
+	}
+
+	fmt.Fprintf(out, format, value, id)
+	return nil
+}
+
+// Parses a list of key=value pairs, returns nil for an empty list
+func ParseProperties(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	properties := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid property '%s', must be key=value", value)
+		}
+		properties[parts[0]] = parts[1]
+	}
+
+	return properties, nil
+}
+
+func checkAppProperties(properties map[string]string) error {
+	for key := range properties {
+		if reservedAppProperties[key] {
+			return fmt.Errorf("App property '%s' is used by gdrive and can not be changed", key)
+		}
+	}
+	return nil
+}
@@ -9,10 +9,12 @@ import (
 const DirectoryMimeType = "application/vnd.google-apps.folder"
 
 type MkdirArgs struct {
-	Out         io.Writer
-	Name        string
-	Description string
-	Parents     []string
+	Out           io.Writer
+	Name          string
+	Description   string
+	Parents       []string
+	AppProperties map[string]string
+	Properties    map[string]string
 }
 
 func (self *Drive) Mkdir(args MkdirArgs) error {
@@ -26,19 +28,96 @@ func (self *Drive) Mkdir(args MkdirArgs) error {
 
 func (self *Drive) mkdir(args MkdirArgs) (*drive.File, error) {
 	dstFile := &drive.File{
-		Name:        args.Name,
-		Description: args.Description,
-		MimeType:    DirectoryMimeType,
+		Name:          args.Name,
+		Description:   args.Description,
+		MimeType:      DirectoryMimeType,
+		AppProperties: args.AppProperties,
+		Properties:    args.Properties,
 	}
 
 	// Set parent folders
-	dstFile.Parents = args.Parents
+	dstFile.Parents = self.defaultParents(args.Parents)
 
 	// Create directory
-	f, err := self.service.Files.Create(dstFile).Do()
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Do()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create directory: %s", err)
 	}
 
 	return f, nil
 }
+
+type MkdirAllArgs struct {
+	Out         io.Writer
+	Path        string
+	Description string
+	Properties  map[string]string
+}
+
+// MkdirAll creates the directories of the path that does not exist,
+// and prints the id of the last directory
+func (self *Drive) MkdirAll(args MkdirAllArgs) error {
+	id, err := self.mkdirAll(args.Path, args.Description, args.Properties)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(args.Out, id)
+	return nil
+}
+
+// Returns the id of the directory at the given path from the
+// root of my drive, any missing directories are created
+func (self *Drive) mkdirAll(path, description string, properties map[string]string) (string, error) {
+	names := splitRemotePath(path)
+	if len(names) == 0 {
+		return "", fmt.Errorf("Path '%s' does not contain any directories", path)
+	}
+
+	parent := &drive.File{Id: self.rootId()}
+
+	for i, name := range names {
+		f, err := self.findChild(parent.Id, name)
+		if err != nil {
+			return "", err
+		}
+
+		if f != nil {
+			if !isDir(f) {
+				return "", fmt.Errorf("'%s' in %s exists and is not a directory", name, path)
+			}
+			parent = f
+			continue
+		}
+
+		if _, ok := parent.AppProperties["sync"]; ok {
+			return "", fmt.Errorf("Can not create '%s' in the sync directory %s, use 'sync upload' instead", name, parent.Id)
+		}
+
+		mkdirArgs := MkdirArgs{
+			Name:    name,
+			Parents: []string{parent.Id},
+		}
+
+		// The description and properties are only given to the last directory
+		if i == len(names)-1 {
+			mkdirArgs.Description = description
+			mkdirArgs.Properties = properties
+		}
+
+		parent, err = self.mkdir(mkdirArgs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return parent.Id, nil
+}
+
+// Returns the id of the parent, remote paths are resolved and
+// the missing directories of the path are created if create is set
+func (self *Drive) resolveParentId(idOrPath string, create bool) (string, error) {
+	if create && IsRemotePath(idOrPath) {
+		return self.mkdirAll(idOrPath, "", nil)
+	}
+	return self.resolveId(idOrPath)
+}
@@ -0,0 +1,162 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"strings"
+)
+
+type MoveArgs struct {
+	Out              io.Writer
+	Ids              []string
+	ParentId         string
+	KeepOtherParents bool
+}
+
+func (self *Drive) Move(args MoveArgs) error {
+	parentId, err := self.resolveId(args.ParentId)
+	if err != nil {
+		return err
+	}
+
+	parent, err := self.service.Files.Get(parentId).SupportsAllDrives(true).Fields("id", "name", "mimeType", "appProperties").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get parent: %s", err)
+	}
+
+	if !isDir(parent) {
+		return fmt.Errorf("'%s' is not a directory", parent.Name)
+	}
+
+	for _, idOrPath := range args.Ids {
+		id, err := self.resolveId(idOrPath)
+		if err != nil {
+			return err
+		}
+
+		err = self.moveFile(id, parent, args)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (self *Drive) moveFile(id string, parent *drive.File, args MoveArgs) error {
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType", "parents", "appProperties").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	srcRootId := f.AppProperties["syncRootId"]
+	dstRootId := syncRootIdOf(parent)
+
+	if dstRootId != "" {
+		if _, ok := f.AppProperties["syncRoot"]; ok {
+			return fmt.Errorf("'%s' is a sync root and can not be moved into another sync directory", f.Name)
+		}
+	}
+
+	// Files in sync directories must have exactly one parent
+	if args.KeepOtherParents && (srcRootId != "" || dstRootId != "") {
+		return fmt.Errorf("--keep-other-parents is not allowed when moving into or out of sync directories")
+	}
+
+	// Sync will fail on name collisions, so check for them up front
+	if dstRootId != "" {
+		existing, err := self.findChild(parent.Id, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil && existing.Id != f.Id {
+			return fmt.Errorf("'%s' already exists in the sync directory '%s'", f.Name, parent.Name)
+		}
+	}
+
+	call := self.service.Files.Update(f.Id, syncPropertiesUpdate(srcRootId, dstRootId)).SupportsAllDrives(true).AddParents(parent.Id)
+	if !args.KeepOtherParents {
+		call = call.RemoveParents(strings.Join(f.Parents, ","))
+	}
+
+	_, err = call.Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to move file: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Moved '%s' to '%s'\n", f.Name, parent.Name)
+
+	// Update sync properties of the directory content
+	if srcRootId != dstRootId && isDir(f) {
+		count, err := self.updateSyncRootId(f.Id, srcRootId, dstRootId)
+		if err != nil {
+			return fmt.Errorf("Moved '%s', but failed to update sync properties of its content: %s", f.Name, err)
+		}
+
+		fmt.Fprintf(args.Out, "Updated sync properties of %d files in '%s'\n", count, f.Name)
+	}
+
+	return nil
+}
+
+// Returns the sync root id of a directory, or an empty string
+// if the directory is not part of a sync root
+func syncRootIdOf(f *drive.File) string {
+	if _, ok := f.AppProperties["syncRoot"]; ok {
+		return f.Id
+	}
+	return f.AppProperties["syncRootId"]
+}
+
+// Returns the file changes needed to move a file between sync roots,
+// an empty root id means that the file is not part of a sync root
+func syncPropertiesUpdate(srcRootId, dstRootId string) *drive.File {
+	if srcRootId == dstRootId {
+		return &drive.File{}
+	}
+
+	if dstRootId == "" {
+		return &drive.File{
+			NullFields: []string{"AppProperties.sync", "AppProperties.syncRootId"},
+		}
+	}
+
+	return &drive.File{
+		AppProperties: map[string]string{"sync": "true", "syncRootId": dstRootId},
+	}
+}
+
+// Updates the sync properties of all files below the given directory
+func (self *Drive) updateSyncRootId(dirId, srcRootId, dstRootId string) (int, error) {
+	listArgs := listAllFilesArgs{
+		query:  fmt.Sprintf("'%s' in parents and trashed = false", dirId),
+		fields: []googleapi.Field{"nextPageToken", "files(id,mimeType)"},
+	}
+	files, err := self.listAllFiles(listArgs)
+	if err != nil {
+		return 0, fmt.Errorf("Failed listing files: %s", err)
+	}
+
+	count := 0
+
+	for _, f := range files {
+		_, err = self.service.Files.Update(f.Id, syncPropertiesUpdate(srcRootId, dstRootId)).SupportsAllDrives(true).Fields("id").Do()
+		if err != nil {
+			return count, fmt.Errorf("Failed to update %s: %s", f.Id, err)
+		}
+		count++
+
+		if isDir(f) {
+			n, err := self.updateSyncRootId(f.Id, srcRootId, dstRootId)
+			count += n
+			if err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, nil
+}
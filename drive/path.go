@@ -4,8 +4,71 @@ import (
 	"fmt"
 	"google.golang.org/api/drive/v3"
 	"path/filepath"
+	"strings"
 )
 
+// Remote paths are given from the root of my drive, i.e. drive:/Photos/2016
+const RemotePathPrefix = "drive:"
+
+func IsRemotePath(s string) bool {
+	return strings.HasPrefix(s, RemotePathPrefix)
+}
+
+// Returns the id of the file at the given remote path,
+// or the argument as is if it is not a remote path
+func (self *Drive) resolveId(idOrPath string) (string, error) {
+	if !IsRemotePath(idOrPath) {
+		return idOrPath, nil
+	}
+
+	parentId := self.rootId()
+
+	for _, name := range splitRemotePath(idOrPath) {
+		f, err := self.findChild(parentId, name)
+		if err != nil {
+			return "", err
+		}
+
+		if f == nil {
+			return "", fmt.Errorf("Could not find '%s' in %s", name, idOrPath)
+		}
+
+		parentId = f.Id
+	}
+
+	return parentId, nil
+}
+
+// Returns the path names of a remote path, without the prefix
+func splitRemotePath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(strings.TrimPrefix(path, RemotePathPrefix), "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Returns the child with the given name, or nil if there is none
+func (self *Drive) findChild(parentId, name string) (*drive.File, error) {
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false", escapeQuery(name), parentId)
+
+	fileList, err := self.filesList().Q(query).Fields("files(id,name,mimeType,appProperties)").Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find '%s': %s", name, err)
+	}
+
+	switch len(fileList.Files) {
+	case 0:
+		return nil, nil
+	case 1:
+		return fileList.Files[0], nil
+	}
+
+	return nil, fmt.Errorf("Found %d files named '%s' in the same directory, use the file id instead", len(fileList.Files), name)
+}
+
 func (self *Drive) newPathfinder() *remotePathfinder {
 	return &remotePathfinder{
 		service: self.service.Files,
@@ -53,7 +116,7 @@ func (self *remotePathfinder) getParent(id string) (*drive.File, error) {
 	}
 
 	// Fetch file from drive
-	f, err := self.service.Get(id).Fields("id", "name", "parents").Do()
+	f, err := self.service.Get(id).SupportsAllDrives(true).Fields("id", "name", "parents").Do()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get file: %s", err)
 	}
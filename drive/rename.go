@@ -0,0 +1,394 @@
+package drive
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+type RenameArgs struct {
+	Out  io.Writer
+	Id   string
+	Name string
+}
+
+func (self *Drive) Rename(args RenameArgs) error {
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "parents").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	// Ensure that the new name is not taken in any of the parents
+	for _, parentId := range f.Parents {
+		existing, err := self.findChild(parentId, args.Name)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil && existing.Id != f.Id {
+			return fmt.Errorf("A file named '%s' already exists in the same directory (%s)", args.Name, existing.Id)
+		}
+	}
+
+	err = self.renameFile(f.Id, args.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(args.Out, "Renamed '%s' -> '%s'\n", f.Name, args.Name)
+	return nil
+}
+
+// A rename done by a bulk rename, saved to the mapping file
+type RenameMapping struct {
+	Id       string `json:"id"`
+	ParentId string `json:"parentId"`
+	OldName  string `json:"oldName"`
+	NewName  string `json:"newName"`
+}
+
+type BulkRenameArgs struct {
+	Out         io.Writer
+	Expression  string
+	ParentId    string
+	Recursive   bool
+	DryRun      bool
+	MappingPath string
+}
+
+func (self *Drive) BulkRename(args BulkRenameArgs) error {
+	renamer, err := parseRenameExpression(args.Expression)
+	if err != nil {
+		return err
+	}
+
+	parentId, err := self.resolveId(args.ParentId)
+	if err != nil {
+		return err
+	}
+
+	var mappings []*RenameMapping
+	err = self.planRenames(parentId, "", renamer, args.Recursive, &mappings)
+	if err != nil {
+		return err
+	}
+
+	if len(mappings) == 0 {
+		fmt.Fprintln(args.Out, "No file names matched the expression")
+		return nil
+	}
+
+	if args.DryRun {
+		printRenameMappings(args.Out, mappings)
+		return nil
+	}
+
+	// Save the mapping before renaming, so partial renames can be undone as well
+	err = writeRenameMappings(args.MappingPath, mappings)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range mappings {
+		err = self.renameFile(m.Id, m.NewName)
+		if err != nil {
+			return fmt.Errorf("Renamed %d of %d files: %s", i, len(mappings), err)
+		}
+		fmt.Fprintf(args.Out, "[%04d/%04d] Renamed '%s' -> '%s'\n", i+1, len(mappings), m.OldName, m.NewName)
+	}
+
+	fmt.Fprintf(args.Out, "Saved rename mapping to %s, use 'rename undo %s' to revert\n", args.MappingPath, args.MappingPath)
+	return nil
+}
+
+type UndoRenameArgs struct {
+	Out         io.Writer
+	MappingPath string
+}
+
+func (self *Drive) UndoRename(args UndoRenameArgs) error {
+	mappings, err := readRenameMappings(args.MappingPath)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range mappings {
+		f, err := self.service.Files.Get(m.Id).SupportsAllDrives(true).Fields("id", "name").Do()
+		if err != nil {
+			return fmt.Errorf("Failed to get file: %s", err)
+		}
+
+		// Leave files that have been renamed again
+		if f.Name != m.NewName {
+			fmt.Fprintf(args.Out, "[%04d/%04d] Skipping '%s', expected name '%s'\n", i+1, len(mappings), f.Name, m.NewName)
+			continue
+		}
+
+		existing, err := self.findChild(m.ParentId, m.OldName)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			fmt.Fprintf(args.Out, "[%04d/%04d] Skipping '%s', the name '%s' is taken by %s\n", i+1, len(mappings), f.Name, m.OldName, existing.Id)
+			continue
+		}
+
+		err = self.renameFile(m.Id, m.OldName)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(args.Out, "[%04d/%04d] Renamed '%s' -> '%s'\n", i+1, len(mappings), m.NewName, m.OldName)
+	}
+
+	return nil
+}
+
+// Finds the files below the parent whose name changes with the given renamer
+func (self *Drive) planRenames(parentId, path string, renamer func(string) string, recursive bool, mappings *[]*RenameMapping) error {
+	listArgs := listAllFilesArgs{
+		query:  fmt.Sprintf("'%s' in parents and trashed = false", parentId),
+		fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType)"},
+	}
+	files, err := self.listAllFiles(listArgs)
+	if err != nil {
+		return fmt.Errorf("Failed listing files: %s", err)
+	}
+
+	dirMappings, err := planDirRenames(files, parentId, path, renamer)
+	if err != nil {
+		return err
+	}
+	*mappings = append(*mappings, dirMappings...)
+
+	if !recursive {
+		return nil
+	}
+
+	for _, f := range files {
+		if !isDir(f) {
+			continue
+		}
+
+		err = self.planRenames(f.Id, filepath.Join(path, renamer(f.Name)), renamer, recursive, mappings)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the renames of the files in a directory, the renames are rejected if
+// a new name is empty or if two files in the directory would get the same name
+func planDirRenames(files []*drive.File, parentId, path string, renamer func(string) string) ([]*RenameMapping, error) {
+	// Names of all files in the directory after renaming, used to detect collisions
+	names := map[string]*drive.File{}
+	var collisions []string
+	var mappings []*RenameMapping
+
+	for _, f := range files {
+		newName := renamer(f.Name)
+
+		if newName == "" {
+			return nil, fmt.Errorf("Renaming '%s' gives an empty name", filepath.Join(path, f.Name))
+		}
+
+		if other, found := names[newName]; found {
+			collisions = append(collisions, fmt.Sprintf("%s and %s would both be named '%s'", other.Id, f.Id, filepath.Join(path, newName)))
+		}
+		names[newName] = f
+
+		if newName != f.Name {
+			mappings = append(mappings, &RenameMapping{
+				Id:       f.Id,
+				ParentId: parentId,
+				OldName:  f.Name,
+				NewName:  newName,
+			})
+		}
+	}
+
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("Found name collisions, no files were renamed:\n%s", strings.Join(collisions, "\n"))
+	}
+
+	return mappings, nil
+}
+
+func (self *Drive) renameFile(id, name string) error {
+	_, err := self.service.Files.Update(id, &drive.File{Name: name}).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to rename file: %s", err)
+	}
+	return nil
+}
+
+// Parses a sed style substitution, i.e. s/IMG_(\d+)/photo-$1/g.
+// The g flag replaces all matches and the i flag ignores case.
+// The delimiter can be used in the pattern and replacement when escaped with a backslash
+func parseRenameExpression(expr string) (func(string) string, error) {
+	if len(expr) < 2 || expr[0] != 's' || expr[1] == '\\' {
+		return nil, fmt.Errorf("Invalid expression '%s', expected s/pattern/replacement/", expr)
+	}
+
+	parts := splitExpression(expr[2:], expr[1])
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Invalid expression '%s', expected s/pattern/replacement/", expr)
+	}
+
+	pattern, replacement, flags := parts[0], expandGroupNumbers(parts[1]), parts[2]
+
+	global := false
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf("Invalid flag '%c' in expression '%s'", flag, expr)
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid pattern in expression '%s': %s", expr, err)
+	}
+
+	if global {
+		return func(name string) string {
+			return re.ReplaceAllString(name, replacement)
+		}, nil
+	}
+
+	return func(name string) string {
+		match := re.FindStringSubmatchIndex(name)
+		if match == nil {
+			return name
+		}
+
+		replaced := re.ExpandString(nil, replacement, name, match)
+		return name[:match[0]] + string(replaced) + name[match[1]:]
+	}, nil
+}
+
+// Splits the expression on the delimiter, an escaped delimiter is kept
+// as the delimiter itself and other escapes are left for the regexp
+func splitExpression(expr string, delim byte) []string {
+	var parts []string
+	var part []byte
+
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && i+1 < len(expr) && expr[i+1] == delim:
+			part = append(part, delim)
+			i++
+		case expr[i] == '\\' && i+1 < len(expr):
+			part = append(part, expr[i], expr[i+1])
+			i++
+		case expr[i] == delim:
+			parts = append(parts, string(part))
+			part = nil
+		default:
+			part = append(part, expr[i])
+		}
+	}
+
+	return append(parts, string(part))
+}
+
+// Rewrites $1 to ${1}, the regexp package would otherwise read $1_x
+// as a reference to a group named 1_x and replace it with nothing
+func expandGroupNumbers(replacement string) string {
+	var result []byte
+
+	for i := 0; i < len(replacement); i++ {
+		if replacement[i] != '$' || i+1 == len(replacement) {
+			result = append(result, replacement[i])
+			continue
+		}
+
+		// $$ is a literal $
+		if replacement[i+1] == '$' {
+			result = append(result, '$', '$')
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(replacement) && replacement[j] >= '0' && replacement[j] <= '9' {
+			j++
+		}
+
+		if j == i+1 {
+			result = append(result, '$')
+			continue
+		}
+
+		result = append(result, "${"+replacement[i+1:j]+"}"...)
+		i = j - 1
+	}
+
+	return string(result)
+}
+
+func printRenameMappings(out io.Writer, mappings []*RenameMapping) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Id\tName\tNew Name")
+
+	for _, m := range mappings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", m.Id, m.OldName, m.NewName)
+	}
+
+	w.Flush()
+}
+
+func writeRenameMappings(path string, mappings []*RenameMapping) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create mapping file: %s", err)
+	}
+	defer f.Close()
+
+	err = json.NewEncoder(f).Encode(mappings)
+	if err != nil {
+		return fmt.Errorf("Failed to write mapping file: %s", err)
+	}
+	return nil
+}
+
+func readRenameMappings(path string) ([]*RenameMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open mapping file: %s", err)
+	}
+	defer f.Close()
+
+	var mappings []*RenameMapping
+	err = json.NewDecoder(f).Decode(&mappings)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read mapping file: %s", err)
+	}
+
+	// Undo renames in reverse order
+	for i, j := 0, len(mappings)-1; i < j; i, j = i+1, j-1 {
+		mappings[i], mappings[j] = mappings[j], mappings[i]
+	}
+
+	return mappings, nil
+}
@@ -0,0 +1,141 @@
+package drive
+
+import (
+	"google.golang.org/api/drive/v3"
+	"testing"
+)
+
+func TestParseRenameExpression(t *testing.T) {
+	cases := []struct {
+		expr string
+		name string
+		want string
+	}{
+		{`s/IMG_(\d+)/photo-$1/`, "IMG_0001.jpg", "photo-0001.jpg"},
+		{`s/IMG_(\d+)/photo-$1/`, "DSC_0001.jpg", "DSC_0001.jpg"},
+		{`s/a/b/`, "banana", "bbnana"},
+		{`s/a/b/g`, "banana", "bbnbnb"},
+		{`s/A/b/i`, "banana", "bbnana"},
+		{`s/A/b/gi`, "banana", "bbnbnb"},
+		{`s|/|-|g`, "a/b/c", "a-b-c"},
+		{`s/\.JPG$/.jpg/`, "IMG.JPG", "IMG.jpg"},
+		{`s/(\w+)-(\w+)/${2}_$1/`, "first-second.txt", "second_first.txt"},
+		{`s/^/2016-/`, "report.pdf", "2016-report.pdf"},
+
+		// Escaped delimiters
+		{`s/a\/b/c/`, "a/b.txt", "c.txt"},
+		{`s/-/\//g`, "a-b-c", "a/b/c"},
+		{`s|\.|\||`, "a.b", "a|b"},
+
+		// Group numbers followed by name characters
+		{`s/IMG_(\d+)/$1_photo/`, "IMG_0001.jpg", "0001_photo.jpg"},
+		{`s/(\w+)-(\w+)/$2x$1/`, "a-b", "bxa"},
+		{`s/(\w+)/${1}_x/`, "a", "a_x"},
+		{`s/(?P<num>\d+)/$num/`, "IMG_1", "IMG_1"},
+		{`s/a/$$1/`, "a", "$1"},
+		{`s/a/b$/`, "a", "b$"},
+	}
+
+	for _, c := range cases {
+		renamer, err := parseRenameExpression(c.expr)
+		if err != nil {
+			t.Errorf("parseRenameExpression(%q) failed: %s", c.expr, err)
+			continue
+		}
+
+		if got := renamer(c.name); got != c.want {
+			t.Errorf("parseRenameExpression(%q) renamed %q to %q, want %q", c.expr, c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseRenameExpressionErrors(t *testing.T) {
+	exprs := []string{
+		"",
+		"s",
+		"x/a/b/",
+		"s/a/b",
+		"s/a/b/c/",
+		"s/a/b/x",
+		"s/(/b/",
+		`s\a\b\`,
+		`s/a\/b/`,
+	}
+
+	for _, expr := range exprs {
+		if _, err := parseRenameExpression(expr); err == nil {
+			t.Errorf("parseRenameExpression(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestPlanDirRenames(t *testing.T) {
+	files := func(names ...string) []*drive.File {
+		var result []*drive.File
+		for i, name := range names {
+			result = append(result, &drive.File{Id: string(rune('a' + i)), Name: name})
+		}
+		return result
+	}
+
+	cases := []struct {
+		expr    string
+		files   []*drive.File
+		want    map[string]string
+		wantErr bool
+	}{
+		// Only files whose name changes are renamed
+		{`s/IMG_/photo-/`, files("IMG_1.jpg", "notes.txt"), map[string]string{"IMG_1.jpg": "photo-1.jpg"}, false},
+
+		// Two files getting the same name
+		{`s/\d+//`, files("a1.txt", "a2.txt"), nil, true},
+
+		// A new name taken by an unchanged file
+		{`s/IMG_//`, files("IMG_1.jpg", "1.jpg"), nil, true},
+
+		// Swapping names is not a collision
+		{`s/^(a|b)$/x$1/`, files("a", "b"), map[string]string{"a": "xa", "b": "xb"}, false},
+
+		// Empty names
+		{`s/.*//`, files("a.txt"), nil, true},
+	}
+
+	for _, c := range cases {
+		renamer, err := parseRenameExpression(c.expr)
+		if err != nil {
+			t.Fatalf("parseRenameExpression(%q) failed: %s", c.expr, err)
+		}
+
+		mappings, err := planDirRenames(c.files, "parent", "", renamer)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("planDirRenames with %q succeeded, want error", c.expr)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("planDirRenames with %q failed: %s", c.expr, err)
+			continue
+		}
+
+		got := map[string]string{}
+		for _, m := range mappings {
+			if m.ParentId != "parent" {
+				t.Errorf("planDirRenames with %q gave parent %q", c.expr, m.ParentId)
+			}
+			got[m.OldName] = m.NewName
+		}
+
+		if len(got) != len(c.want) {
+			t.Errorf("planDirRenames with %q = %v, want %v", c.expr, got, c.want)
+			continue
+		}
+
+		for oldName, newName := range c.want {
+			if got[oldName] != newName {
+				t.Errorf("planDirRenames with %q renamed %q to %q, want %q", c.expr, oldName, got[oldName], newName)
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package drive
+
+import (
+	"bytes"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+const DefaultDiffContext = 3
+
+// Revisions larger than this are compared by checksum only
+const maxDiffSize = 10 * 1000 * 1000
+
+// Max size of the table used to find the longest common subsequence of lines
+const maxDiffCells = 10 * 1000 * 1000
+
+type DiffRevisionsArgs struct {
+	Out         io.Writer
+	FileId      string
+	RevisionA   string
+	RevisionB   string
+	Context     int
+	SizeInBytes bool
+}
+
+// Prints a unified diff of text revisions, or a summary
+// of the size and checksum of binary revisions
+func (self *Drive) DiffRevisions(args DiffRevisionsArgs) error {
+	revA, err := self.getDiffRevision(args.FileId, args.RevisionA)
+	if err != nil {
+		return err
+	}
+
+	revB, err := self.getDiffRevision(args.FileId, args.RevisionB)
+	if err != nil {
+		return err
+	}
+
+	if revA.Size <= maxDiffSize && revB.Size <= maxDiffSize {
+		a, err := self.revisionContent(args.FileId, revA.Id)
+		if err != nil {
+			return err
+		}
+
+		b, err := self.revisionContent(args.FileId, revB.Id)
+		if err != nil {
+			return err
+		}
+
+		if isText(a) && isText(b) {
+			return unifiedDiff(args.Out, revisionLabel(revA), revisionLabel(revB), splitLines(a), splitLines(b), args.Context)
+		}
+	}
+
+	printRevisionSummary(args.Out, revA, revB, args.SizeInBytes)
+	return nil
+}
+
+func (self *Drive) getDiffRevision(fileId, revId string) (*drive.Revision, error) {
+	rev, err := self.service.Revisions.Get(fileId, revId).Fields("id", "originalFilename", "size", "md5Checksum", "modifiedTime").Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get revision: %s", err)
+	}
+
+	if rev.OriginalFilename == "" {
+		return nil, fmt.Errorf("Comparing revisions for this file type is not supported")
+	}
+
+	return rev, nil
+}
+
+func (self *Drive) revisionContent(fileId, revId string) ([]byte, error) {
+	res, err := self.service.Revisions.Get(fileId, revId).Download()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to download revision: %s", err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to download revision: %s", err)
+	}
+	return data, nil
+}
+
+func revisionLabel(rev *drive.Revision) string {
+	return fmt.Sprintf("%s (%s)", rev.OriginalFilename, rev.Id)
+}
+
+func printRevisionSummary(out io.Writer, revA, revB *drive.Revision, sizeInBytes bool) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Revision\tSize\tMd5sum\tModified")
+	for _, rev := range []*drive.Revision{revA, revB} {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rev.Id, formatSize(rev.Size, sizeInBytes), rev.Md5Checksum, formatDatetime(rev.ModifiedTime))
+	}
+	w.Flush()
+
+	if revA.Md5Checksum == revB.Md5Checksum {
+		fmt.Fprintln(out, "Content is identical")
+	} else {
+		fmt.Fprintln(out, "Content differs")
+	}
+}
+
+// Content is considered text when it is valid utf8 without null bytes
+func isText(data []byte) bool {
+	return bytes.IndexByte(data, 0) == -1 && utf8.Valid(data)
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// Prints the differences as unified diff hunks with the given number of context lines
+func unifiedDiff(out io.Writer, nameA, nameB string, a, b []string, context int) error {
+	ops, err := diffLines(a, b)
+	if err != nil {
+		return err
+	}
+
+	printedHeader := false
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Changes separated by at most twice the context are in the same hunk
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		lastChange := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				lastChange = j
+			} else if j-lastChange > 2*context {
+				break
+			}
+		}
+		end := min(len(ops), lastChange+context+1)
+
+		if !printedHeader {
+			fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+			printedHeader = true
+		}
+
+		printHunk(out, ops, start, end)
+		i = end
+	}
+
+	return nil
+}
+
+func printHunk(out io.Writer, ops []diffOp, start, end int) {
+	// Line numbers of the hunk start in both revisions
+	lineA, lineB := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			lineA++
+		}
+		if op.kind != '-' {
+			lineB++
+		}
+	}
+
+	countA, countB := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			countA++
+		}
+		if op.kind != '-' {
+			countB++
+		}
+	}
+
+	// An empty range refers to the line before it
+	if countA == 0 {
+		lineA--
+	}
+	if countB == 0 {
+		lineB--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+	for _, op := range ops[start:end] {
+		fmt.Fprintf(out, "%c%s\n", op.kind, op.line)
+	}
+}
+
+// Returns the edit script from a to b based on the longest common
+// subsequence of lines, common prefix and suffix lines are skipped first
+func diffLines(a, b []string) ([]diffOp, error) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		return nil, fmt.Errorf("Revisions are too different to compare")
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	n, m := len(midA), len(midB)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops, nil
+}
@@ -0,0 +1,121 @@
+package drive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		a       string
+		b       string
+		changes int
+	}{
+		{"", "", 0},
+		{"a b c", "a b c", 0},
+		{"", "a b", 2},
+		{"a b", "", 2},
+		{"a b c", "a x c", 2},
+		{"a b c d", "a c d e", 2},
+		{"a b c a b b a", "c b a b a c", 5},
+	}
+
+	for _, c := range cases {
+		a, b := strings.Fields(c.a), strings.Fields(c.b)
+
+		ops, err := diffLines(a, b)
+		if err != nil {
+			t.Errorf("diffLines(%q, %q) failed: %s", c.a, c.b, err)
+			continue
+		}
+
+		// Both revisions must be recreated from the edit script
+		var gotA, gotB []string
+		changes := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				changes++
+			}
+		}
+
+		if strings.Join(gotA, " ") != c.a || strings.Join(gotB, " ") != c.b {
+			t.Errorf("diffLines(%q, %q) gave revisions %q and %q", c.a, c.b, gotA, gotB)
+		}
+
+		if changes != c.changes {
+			t.Errorf("diffLines(%q, %q) gave %d changes, want %d", c.a, c.b, changes, c.changes)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		a       string
+		b       string
+		context int
+		want    string
+	}{
+		// No differences
+		{"a b c", "a b c", 3, ""},
+
+		// Changed line
+		{"a b c", "a x c", 1, "@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+
+		// Added to and removed from empty revisions
+		{"", "x", 3, "@@ -0,0 +1,1 @@\n+x\n"},
+		{"x", "", 3, "@@ -1,1 +0,0 @@\n-x\n"},
+
+		// Empty ranges without context refer to the line before it
+		{"a b", "a x b", 0, "@@ -1,0 +2,1 @@\n+x\n"},
+		{"a x b", "a b", 0, "@@ -2,1 +1,0 @@\n-x\n"},
+
+		// Changes more than twice the context apart are separate hunks
+		{
+			"1 2 3 4 5 6 7 8 9 10",
+			"1 two 3 4 5 6 7 8 nine 10",
+			1,
+			"@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+nine\n 10\n",
+		},
+
+		// Changes at most twice the context apart are the same hunk
+		{
+			"1 2 3 4 5 6",
+			"1 two 3 4 five 6",
+			1,
+			"@@ -1,6 +1,6 @@\n 1\n-2\n+two\n 3\n 4\n-5\n+five\n 6\n",
+		},
+
+		// Lines added in the second hunk shift the line numbers
+		{
+			"1 2 3 4 5 6 7 8",
+			"1 new 2 3 4 5 6 7 8 end",
+			0,
+			"@@ -1,0 +2,1 @@\n+new\n@@ -8,0 +10,1 @@\n+end\n",
+		},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		err := unifiedDiff(&out, "a.txt", "b.txt", strings.Fields(c.a), strings.Fields(c.b), c.context)
+		if err != nil {
+			t.Errorf("unifiedDiff(%q, %q) failed: %s", c.a, c.b, err)
+			continue
+		}
+
+		want := c.want
+		if want != "" {
+			want = "--- a.txt\n+++ b.txt\n" + want
+		}
+
+		if got := out.String(); got != want {
+			t.Errorf("unifiedDiff(%q, %q, %d) =\n%s\nwant\n%s", c.a, c.b, c.context, got, want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ type DownloadRevisionArgs struct {
 	Force      bool
 	Stdout     bool
 	Timeout    time.Duration
+	Cipher     *Cipher
 }
 
 func (self *Drive) DownloadRevision(args DownloadRevisionArgs) (err error) {
@@ -45,6 +46,21 @@ func (self *Drive) DownloadRevision(args DownloadRevisionArgs) (err error) {
 	// Close body on function exit
 	defer res.Body.Close()
 
+	body := timeoutReaderWrapper(res.Body)
+	name := rev.OriginalFilename
+
+	// Revisions have no app properties, decrypt anything that looks encrypted
+	if args.Cipher != nil {
+		body, err = args.Cipher.MaybeDecryptReader(body)
+		if err != nil {
+			return err
+		}
+
+		if plain, err := args.Cipher.DecryptName(name); err == nil {
+			name = plain
+		}
+	}
+
 	// Discard other output if file is written to stdout
 	out := args.Out
 	if args.Stdout {
@@ -52,13 +68,13 @@ func (self *Drive) DownloadRevision(args DownloadRevisionArgs) (err error) {
 	}
 
 	// Path to file
-	fpath := filepath.Join(args.Path, rev.OriginalFilename)
+	fpath := filepath.Join(args.Path, name)
 
-	fmt.Fprintf(out, "Downloading %s -> %s\n", rev.OriginalFilename, fpath)
+	fmt.Fprintf(out, "Downloading %s -> %s\n", name, fpath)
 
 	bytes, rate, err := self.saveFile(saveFileArgs{
 		out:           args.Out,
-		body:          timeoutReaderWrapper(res.Body),
+		body:          body,
 		contentLength: res.ContentLength,
 		fpath:         fpath,
 		force:         args.Force,
@@ -0,0 +1,32 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+)
+
+type KeepRevisionArgs struct {
+	Out        io.Writer
+	FileId     string
+	RevisionId string
+	Off        bool
+}
+
+// Revisions kept forever are not purged automatically
+func (self *Drive) KeepRevision(args KeepRevisionArgs) error {
+	// KeepForever must be sent explicitly when false
+	revision := &drive.Revision{KeepForever: !args.Off, ForceSendFields: []string{"KeepForever"}}
+
+	_, err := self.service.Revisions.Update(args.FileId, args.RevisionId, revision).Fields("id", "keepForever").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to update revision: %s", err)
+	}
+
+	if args.Off {
+		fmt.Fprintf(args.Out, "Revision '%s' is no longer kept forever\n", args.RevisionId)
+	} else {
+		fmt.Fprintf(args.Out, "Revision '%s' is kept forever\n", args.RevisionId)
+	}
+	return nil
+}
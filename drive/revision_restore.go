@@ -0,0 +1,74 @@
+package drive
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+type RestoreRevisionArgs struct {
+	Out        io.Writer
+	Progress   io.Writer
+	FileId     string
+	RevisionId string
+	ChunkSize  int64
+	Timeout    time.Duration
+}
+
+// Makes the revision the current content of the file by uploading it as a new revision
+func (self *Drive) RestoreRevision(args RestoreRevisionArgs) error {
+	f, err := self.service.Files.Get(args.FileId).SupportsAllDrives(true).Fields("id", "name", "appProperties").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	// The plaintext checksum of the file would not match the restored content
+	if isEncrypted(f) {
+		return fmt.Errorf("'%s' is encrypted, restoring revisions of encrypted files is not supported", f.Name)
+	}
+
+	getRev := self.service.Revisions.Get(args.FileId, args.RevisionId)
+
+	rev, err := getRev.Fields("originalFilename", "mimeType").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get revision: %s", err)
+	}
+
+	if rev.OriginalFilename == "" {
+		return fmt.Errorf("Restoring revisions for this file type is not supported")
+	}
+
+	res, err := getRev.Download()
+	if err != nil {
+		return fmt.Errorf("Failed to download revision: %s", err)
+	}
+	defer res.Body.Close()
+
+	// The revision is saved to a temporary file so it can be uploaded with update
+	tmpFile, err := ioutil.TempFile("", "gdrive-revision-")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary file: %s", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = io.Copy(tmpFile, res.Body)
+	tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to download revision: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Restoring revision '%s' of %s\n", args.RevisionId, f.Name)
+
+	return self.Update(UpdateArgs{
+		Out:       args.Out,
+		Progress:  args.Progress,
+		Id:        args.FileId,
+		Path:      tmpFile.Name(),
+		Name:      f.Name,
+		Mime:      rev.MimeType,
+		ChunkSize: args.ChunkSize,
+		Timeout:   args.Timeout,
+	})
+}
@@ -2,9 +2,13 @@ package drive
 
 import (
 	"fmt"
+	"golang.org/x/net/context"
 	"google.golang.org/api/drive/v3"
 	"io"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 )
 
 type ShareArgs struct {
@@ -15,69 +19,320 @@ type ShareArgs struct {
 	Email        string
 	Domain       string
 	Discoverable bool
+	Expires      string
+	Notify       bool
+	Message      string
+	Recursive    bool
 }
 
 func (self *Drive) Share(args ShareArgs) error {
+	if args.Role == "owner" && self.sharedDriveId != "" {
+		return fmt.Errorf("Files in shared drives are owned by the shared drive, ownership can not be given away")
+	}
+
+	expirationTime, err := parseExpiration(args.Expires, time.Now())
+	if err != nil {
+		return err
+	}
+
 	permission := &drive.Permission{
 		AllowFileDiscovery: args.Discoverable,
 		Role:               args.Role,
 		Type:               args.Type,
 		EmailAddress:       args.Email,
 		Domain:             args.Domain,
+		ExpirationTime:     expirationTime,
 	}
 
-	_, err := self.service.Permissions.Create(args.FileId, permission).Do()
+	err = self.createPermission(args.FileId, permission, args.Notify, args.Message)
 	if err != nil {
-		return fmt.Errorf("Failed to share file: %s", err)
+		return err
 	}
 
 	fmt.Fprintf(args.Out, "Granted %s permission to %s\n", args.Role, args.Type)
+
+	if !args.Recursive {
+		return nil
+	}
+
+	// Only the share of the root sends a notification
+	return self.shareDescendants(args.Out, args.FileId, permission)
+}
+
+func (self *Drive) shareDescendants(out io.Writer, rootId string, permission *drive.Permission) error {
+	folderIds, err := self.listFolderIds(rootId)
+	if err != nil {
+		return err
+	}
+
+	query := &findQuery{
+		terms:  []string{"trashed = false"},
+		filter: func(*drive.File) bool { return true },
+	}
+
+	files, err := self.findFiles(query, folderIds)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = self.createPermission(f.Id, permission, false, "")
+		if err != nil {
+			return fmt.Errorf("%s: %s", f.Name, err)
+		}
+	}
+
+	fmt.Fprintf(out, "Granted %s permission to %s on %d descendants\n", permission.Role, permission.Type, len(files))
 	return nil
 }
 
+// Notifications can only be sent to users and groups,
+// the message is only used when a notification is sent
+func (self *Drive) createPermission(fileId string, permission *drive.Permission, notify bool, message string) error {
+	call := self.service.Permissions.Create(fileId, permission).SupportsAllDrives(true)
+
+	if permission.Type == "user" || permission.Type == "group" {
+		call = call.SendNotificationEmail(notify)
+		if notify && message != "" {
+			call = call.EmailMessage(message)
+		}
+	}
+
+	_, err := call.Do()
+	if err != nil {
+		return fmt.Errorf("Failed to share file: %s", err)
+	}
+
+	return nil
+}
+
+// Parses an expiration given as a date, a datetime or a
+// duration from now in days, weeks or hours, i.e. 7d, 2w or 12h
+func parseExpiration(value string, now time.Time) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	t, err := parseExpirationTime(value, now)
+	if err != nil {
+		return "", err
+	}
+
+	if !t.After(now) {
+		return "", fmt.Errorf("Expiration '%s' is not in the future", value)
+	}
+
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+func parseExpirationTime(value string, now time.Time) (time.Time, error) {
+	units := map[string]time.Duration{
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	if unit, ok := units[value[len(value)-1:]]; ok {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("Invalid expiration '%s', expected i.e. 7d, 2w or 2006-01-02", value)
+		}
+		return now.Add(time.Duration(n) * unit), nil
+	}
+
+	// A date means the end of that day, not the start of it
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	return parseDatetime(value)
+}
+
 type RevokePermissionArgs struct {
 	Out          io.Writer
 	FileId       string
 	PermissionId string
 }
 
+// The permission is given by id or email address
 func (self *Drive) RevokePermission(args RevokePermissionArgs) error {
-	err := self.service.Permissions.Delete(args.FileId, args.PermissionId).Do()
+	permissionId := args.PermissionId
+	if strings.Contains(permissionId, "@") {
+		p, err := self.findPermission(args.FileId, permissionId, false)
+		if err != nil {
+			return err
+		}
+		permissionId = p.Id
+	}
+
+	err := self.service.Permissions.Delete(args.FileId, permissionId).SupportsAllDrives(true).Do()
 	if err != nil {
-		fmt.Errorf("Failed to revoke permission: %s", err)
-		return err
+		return fmt.Errorf("Failed to revoke permission: %s", err)
 	}
 
 	fmt.Fprintf(args.Out, "Permission revoked\n")
 	return nil
 }
 
+type RevokePermissionsArgs struct {
+	Out    io.Writer
+	FileId string
+	Type   string
+	Role   string
+	Email  string
+	Domain string
+}
+
+// Revokes all permissions matching the non-empty filters, owner permissions are skipped
+func (self *Drive) RevokePermissions(args RevokePermissionsArgs) error {
+	permissions, err := self.listAllPermissions(args.FileId, false)
+	if err != nil {
+		return err
+	}
+
+	revoked := 0
+	for _, p := range permissions {
+		if p.Role == "owner" || !matchesPermission(p, args) {
+			continue
+		}
+
+		err = self.service.Permissions.Delete(args.FileId, p.Id).SupportsAllDrives(true).Do()
+		if err != nil {
+			return fmt.Errorf("Failed to revoke permission: %s", err)
+		}
+
+		fmt.Fprintf(args.Out, "Revoked %s permission of %s\n", p.Role, permissionTarget(p))
+		revoked++
+	}
+
+	if revoked == 0 {
+		fmt.Fprintf(args.Out, "No matching permissions\n")
+	}
+	return nil
+}
+
+func matchesPermission(p *drive.Permission, args RevokePermissionsArgs) bool {
+	if args.Type != "" && p.Type != args.Type {
+		return false
+	}
+	if args.Role != "" && p.Role != args.Role {
+		return false
+	}
+	if args.Email != "" && !strings.EqualFold(p.EmailAddress, args.Email) {
+		return false
+	}
+	if args.Domain != "" && !strings.EqualFold(p.Domain, args.Domain) {
+		return false
+	}
+	return true
+}
+
+type UpdatePermissionArgs struct {
+	Out          io.Writer
+	FileId       string
+	PermissionId string
+	Role         string
+	Expires      string
+}
+
+// Changes the role or expiration of an existing permission without
+// revoking it, so no new notification is sent. The permission is
+// given by id or email address
+func (self *Drive) UpdatePermission(args UpdatePermissionArgs) error {
+	if args.Role == "" && args.Expires == "" {
+		return fmt.Errorf("Nothing to update, give a new role or expiration")
+	}
+
+	expirationTime, err := parseExpiration(args.Expires, time.Now())
+	if err != nil {
+		return err
+	}
+
+	p, err := self.findPermission(args.FileId, args.PermissionId, false)
+	if err != nil {
+		return err
+	}
+
+	// The role is required by drive, the current role is kept when not given
+	role := args.Role
+	if role == "" {
+		role = p.Role
+	}
+
+	permission := &drive.Permission{Role: role, ExpirationTime: expirationTime}
+
+	_, err = self.service.Permissions.Update(args.FileId, p.Id, permission).SupportsAllDrives(true).Do()
+	if err != nil {
+		return fmt.Errorf("Failed to update permission: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Updated permission of %s to %s\n", permissionTarget(p), role)
+	return nil
+}
+
 type ListPermissionsArgs struct {
 	Out    io.Writer
 	FileId string
 }
 
 func (self *Drive) ListPermissions(args ListPermissionsArgs) error {
-	permList, err := self.service.Permissions.List(args.FileId).Fields("permissions(id,role,type,domain,emailAddress,allowFileDiscovery)").Do()
+	permissions, err := self.listAllPermissions(args.FileId, false)
 	if err != nil {
-		fmt.Errorf("Failed to list permissions: %s", err)
 		return err
 	}
 
 	printPermissions(printPermissionsArgs{
 		out:         args.Out,
-		permissions: permList.Permissions,
+		permissions: permissions,
 	})
 	return nil
 }
 
+// Lists all permissions of the file or shared drive, admin
+// lists the permissions as a domain administrator
+func (self *Drive) listAllPermissions(fileId string, admin bool) ([]*drive.Permission, error) {
+	permissions, err := self.listPermissions(fileId, admin)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list permissions: %s", err)
+	}
+	return permissions, nil
+}
+
+func (self *Drive) listPermissions(fileId string, admin bool) ([]*drive.Permission, error) {
+	var permissions []*drive.Permission
+
+	call := self.service.Permissions.List(fileId).SupportsAllDrives(true).UseDomainAdminAccess(admin).PageSize(100)
+	err := call.Fields("nextPageToken", "permissions(id,role,type,domain,emailAddress,allowFileDiscovery,expirationTime,permissionDetails)").Pages(context.TODO(), func(pl *drive.PermissionList) error {
+		permissions = append(permissions, pl.Permissions...)
+		return nil
+	})
+
+	return permissions, err
+}
+
+// Returns the permission with the given id, or the permission of the given email address
+func (self *Drive) findPermission(fileId, idOrEmail string, admin bool) (*drive.Permission, error) {
+	permissions, err := self.listAllPermissions(fileId, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range permissions {
+		if p.Id == idOrEmail || strings.EqualFold(p.EmailAddress, idOrEmail) {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Found no permission with id or email '%s' on %s", idOrEmail, fileId)
+}
+
 func (self *Drive) shareAnyoneReader(fileId string) error {
 	permission := &drive.Permission{
 		Role: "reader",
 		Type: "anyone",
 	}
 
-	_, err := self.service.Permissions.Create(fileId, permission).Do()
+	_, err := self.service.Permissions.Create(fileId, permission).SupportsAllDrives(true).Do()
 	if err != nil {
 		return fmt.Errorf("Failed to share file: %s", err)
 	}
@@ -94,16 +349,17 @@ func printPermissions(args printPermissionsArgs) {
 	w := new(tabwriter.Writer)
 	w.Init(args.out, 0, 0, 3, ' ', 0)
 
-	fmt.Fprintln(w, "Id\tType\tRole\tEmail\tDomain\tDiscoverable")
+	fmt.Fprintln(w, "Id\tType\tRole\tEmail\tDomain\tDiscoverable\tExpires")
 
 	for _, p := range args.permissions {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			p.Id,
 			p.Type,
 			p.Role,
 			p.EmailAddress,
 			p.Domain,
 			formatBool(p.AllowFileDiscovery),
+			formatDatetime(p.ExpirationTime),
 		)
 	}
 
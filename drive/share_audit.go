@@ -0,0 +1,374 @@
+package drive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const DefaultAuditExpiring = "7d"
+
+// Issues reported by the sharing audit
+const (
+	AuditAnyone       = "anyone"
+	AuditDiscoverable = "discoverable"
+	AuditExternal     = "external"
+	AuditWriter       = "writer"
+	AuditExpiring     = "expiring"
+)
+
+type ShareAuditArgs struct {
+	Out        io.Writer
+	ParentId   string
+	Recursive  bool
+	Domains    []string
+	Allow      []string
+	Expiring   string
+	Format     string
+	SkipHeader bool
+	Parallel   int
+}
+
+// A permission with one or more issues
+type ShareAuditEntry struct {
+	FileId       string   `json:"fileId"`
+	Name         string   `json:"name"`
+	PermissionId string   `json:"permissionId"`
+	Type         string   `json:"type"`
+	Role         string   `json:"role"`
+	Email        string   `json:"email,omitempty"`
+	Domain       string   `json:"domain,omitempty"`
+	Expires      string   `json:"expires,omitempty"`
+	Issues       []string `json:"issues"`
+}
+
+// Checks the permissions of shared files in the folder, or in all of my
+// drive when no folder is given. Domains are the domains considered internal,
+// by default the domain of the current user. Writers are only checked when
+// allowed writers are given, as emails or domains
+func (self *Drive) ShareAudit(args ShareAuditArgs) error {
+	if err := checkOutputFormat(args.Format); err != nil {
+		return err
+	}
+
+	// Expiring permissions are not reported when no duration is given
+	var expiringBefore time.Time
+	if args.Expiring != "" {
+		t, err := parseExpirationTime(args.Expiring, time.Now())
+		if err != nil {
+			return err
+		}
+		expiringBefore = t
+	}
+
+	domains := args.Domains
+	if len(domains) == 0 {
+		domain, err := self.userDomain()
+		if err != nil {
+			return err
+		}
+		domains = []string{domain}
+	}
+
+	files, err := self.auditFiles(args)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := self.fetchPermissions(files, args.Parallel)
+	if err != nil {
+		return err
+	}
+
+	auditor := &shareAuditor{
+		domains:        toLowerSet(domains),
+		allow:          toLowerSet(args.Allow),
+		expiringBefore: expiringBefore,
+	}
+
+	var entries []*ShareAuditEntry
+	for i, f := range files {
+		for _, p := range permissions[i] {
+			issues := auditor.issues(p)
+			if len(issues) == 0 {
+				continue
+			}
+
+			entries = append(entries, &ShareAuditEntry{
+				FileId:       f.Id,
+				Name:         f.Name,
+				PermissionId: p.Id,
+				Type:         p.Type,
+				Role:         p.Role,
+				Email:        p.EmailAddress,
+				Domain:       p.Domain,
+				Expires:      p.ExpirationTime,
+				Issues:       issues,
+			})
+		}
+	}
+
+	switch args.Format {
+	case FormatCsv:
+		return printShareAuditCsv(args.Out, entries, args.SkipHeader)
+	case FormatJson:
+		return printJson(args.Out, entries)
+	}
+
+	printShareAuditTable(args.Out, entries, args.SkipHeader)
+	return nil
+}
+
+// Returns the shared files to audit, files in shared drives are
+// always audited since they are shared with the drive members
+func (self *Drive) auditFiles(args ShareAuditArgs) ([]*drive.File, error) {
+	terms := []string{"trashed = false"}
+	var files []*drive.File
+	var parentIds []string
+
+	if args.ParentId != "" {
+		id, err := self.resolveId(args.ParentId)
+		if err != nil {
+			return nil, err
+		}
+
+		root, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType", "shared").Do()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get file: %s", err)
+		}
+		files = append(files, root)
+
+		parentIds = []string{id}
+		if args.Recursive {
+			parentIds, err = self.listFolderIds(id)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else if self.sharedDriveId == "" {
+		// Files shared with me are audited by their owners
+		terms = append(terms, "'me' in owners")
+	}
+
+	query := &findQuery{
+		terms:  terms,
+		filter: func(*drive.File) bool { return true },
+	}
+
+	found, err := self.findFiles(query, parentIds)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, found...)
+
+	if self.sharedDriveId != "" {
+		return files, nil
+	}
+
+	var shared []*drive.File
+	for _, f := range files {
+		if f.Shared {
+			shared = append(shared, f)
+		}
+	}
+	return shared, nil
+}
+
+// Lists the permissions of the files with a fixed number of workers,
+// the permissions are returned in the same order as the files
+func (self *Drive) fetchPermissions(files []*drive.File, parallel int) ([][]*drive.Permission, error) {
+	if parallel < 1 {
+		parallel = DefaultListParallel
+	}
+
+	permissions := make([][]*drive.Permission, len(files))
+	errs := make([]error, len(files))
+	jobCh := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// Each worker writes to the indexes it receives only
+			for i := range jobCh {
+				permissions[i], errs[i] = self.listPermissionsRetry(files[i].Id, 0)
+			}
+		}()
+	}
+
+	for i := range files {
+		jobCh <- i
+	}
+	close(jobCh)
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list permissions of '%s': %s", files[i].Name, err)
+		}
+	}
+	return permissions, nil
+}
+
+func (self *Drive) listPermissionsRetry(fileId string, try int) ([]*drive.Permission, error) {
+	permissions, err := self.listPermissions(fileId, false)
+	if err != nil && isBackendOrRateLimitError(err) && try < MaxErrorRetries {
+		exponentialBackoffSleep(try)
+		try++
+		return self.listPermissionsRetry(fileId, try)
+	}
+	return permissions, err
+}
+
+func (self *Drive) userDomain() (string, error) {
+	about, err := self.service.About.Get().Fields("user").Do()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get about: %s", err)
+	}
+
+	return emailDomain(about.User.EmailAddress), nil
+}
+
+type shareAuditor struct {
+	domains        map[string]bool
+	allow          map[string]bool
+	expiringBefore time.Time
+}
+
+func (self *shareAuditor) issues(p *drive.Permission) []string {
+	var issues []string
+
+	if p.Type == "anyone" {
+		issues = append(issues, AuditAnyone)
+	}
+
+	if p.AllowFileDiscovery {
+		issues = append(issues, AuditDiscoverable)
+	}
+
+	if self.isExternal(p) {
+		issues = append(issues, AuditExternal)
+	}
+
+	if len(self.allow) > 0 && isWriterRole(p.Role) && p.Type != "anyone" && !self.isAllowed(p) {
+		issues = append(issues, AuditWriter)
+	}
+
+	if p.ExpirationTime != "" {
+		t, err := time.Parse(time.RFC3339, p.ExpirationTime)
+		if err == nil && t.Before(self.expiringBefore) {
+			issues = append(issues, AuditExpiring)
+		}
+	}
+
+	return issues
+}
+
+func (self *shareAuditor) isExternal(p *drive.Permission) bool {
+	switch p.Type {
+	case "domain":
+		return !self.domains[strings.ToLower(p.Domain)]
+	case "user", "group":
+		return !self.domains[emailDomain(p.EmailAddress)]
+	}
+	return false
+}
+
+func (self *shareAuditor) isAllowed(p *drive.Permission) bool {
+	if p.Type == "domain" {
+		return self.allow[strings.ToLower(p.Domain)]
+	}
+
+	email := strings.ToLower(p.EmailAddress)
+	return self.allow[email] || self.allow[emailDomain(email)]
+}
+
+func isWriterRole(role string) bool {
+	switch role {
+	case "writer", "fileOrganizer", "organizer":
+		return true
+	}
+	return false
+}
+
+func emailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	return strings.ToLower(email[i+1:])
+}
+
+func toLowerSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
+
+func auditTarget(e *ShareAuditEntry) string {
+	if e.Email != "" {
+		return e.Email
+	}
+	return e.Domain
+}
+
+func printShareAuditTable(out io.Writer, entries []*ShareAuditEntry, skipHeader bool) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	if !skipHeader {
+		fmt.Fprintln(w, "FileId\tName\tPermissionId\tType\tRole\tEmail/Domain\tIssues\tExpires")
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.FileId,
+			e.Name,
+			e.PermissionId,
+			e.Type,
+			e.Role,
+			auditTarget(e),
+			strings.Join(e.Issues, ","),
+			formatDatetime(e.Expires),
+		)
+	}
+
+	w.Flush()
+}
+
+func printShareAuditCsv(out io.Writer, entries []*ShareAuditEntry, skipHeader bool) error {
+	w := csv.NewWriter(out)
+
+	if !skipHeader {
+		w.Write([]string{"fileId", "name", "permissionId", "type", "role", "email", "domain", "issues", "expires"})
+	}
+
+	for _, e := range entries {
+		w.Write([]string{
+			e.FileId,
+			e.Name,
+			e.PermissionId,
+			e.Type,
+			e.Role,
+			e.Email,
+			e.Domain,
+			strings.Join(e.Issues, ","),
+			e.Expires,
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Failed to write csv: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package drive
+
+import (
+	"google.golang.org/api/drive/v3"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareAuditorIssues(t *testing.T) {
+	auditor := &shareAuditor{
+		domains:        toLowerSet([]string{"Example.com"}),
+		allow:          toLowerSet([]string{"partner.com", "Contractor@Other.com"}),
+		expiringBefore: time.Date(2016, 1, 9, 0, 0, 0, 0, time.UTC),
+	}
+
+	cases := []struct {
+		permission *drive.Permission
+		want       []string
+	}{
+		// Internal
+		{&drive.Permission{Type: "user", Role: "reader", EmailAddress: "Me@EXAMPLE.com"}, nil},
+		{&drive.Permission{Type: "domain", Role: "reader", Domain: "example.com"}, nil},
+
+		// External readers
+		{&drive.Permission{Type: "user", Role: "reader", EmailAddress: "someone@other.com"}, []string{AuditExternal}},
+		{&drive.Permission{Type: "domain", Role: "reader", Domain: "other.com"}, []string{AuditExternal}},
+
+		// External writers that are not allowed
+		{&drive.Permission{Type: "user", Role: "writer", EmailAddress: "someone@other.com"}, []string{AuditExternal, AuditWriter}},
+		{&drive.Permission{Type: "group", Role: "organizer", EmailAddress: "team@other.com"}, []string{AuditExternal, AuditWriter}},
+
+		// Internal writers must also be allowed
+		{&drive.Permission{Type: "user", Role: "writer", EmailAddress: "me@example.com"}, []string{AuditWriter}},
+
+		// External writers that are allowed by domain or email
+		{&drive.Permission{Type: "user", Role: "writer", EmailAddress: "someone@partner.com"}, []string{AuditExternal}},
+		{&drive.Permission{Type: "user", Role: "fileOrganizer", EmailAddress: "contractor@other.com"}, []string{AuditExternal}},
+		{&drive.Permission{Type: "domain", Role: "writer", Domain: "Partner.com"}, []string{AuditExternal}},
+
+		// Anyone
+		{&drive.Permission{Type: "anyone", Role: "reader"}, []string{AuditAnyone}},
+		{&drive.Permission{Type: "anyone", Role: "writer", AllowFileDiscovery: true}, []string{AuditAnyone, AuditDiscoverable}},
+
+		// Expiration
+		{&drive.Permission{Type: "user", Role: "reader", EmailAddress: "me@example.com", ExpirationTime: "2016-01-08T00:00:00Z"}, []string{AuditExpiring}},
+		{&drive.Permission{Type: "user", Role: "reader", EmailAddress: "me@example.com", ExpirationTime: "2016-01-10T00:00:00Z"}, nil},
+	}
+
+	for _, c := range cases {
+		got := auditor.issues(c.permission)
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("issues(%+v) = %v, want %v", c.permission, got, c.want)
+		}
+	}
+}
+
+func TestShareAuditorIssuesWithoutAllowList(t *testing.T) {
+	auditor := &shareAuditor{domains: toLowerSet([]string{"example.com"})}
+
+	p := &drive.Permission{Type: "user", Role: "writer", EmailAddress: "someone@other.com"}
+	if got := auditor.issues(p); strings.Join(got, ",") != AuditExternal {
+		t.Errorf("issues(%+v) = %v, want %v", p, got, []string{AuditExternal})
+	}
+}
@@ -0,0 +1,190 @@
+package drive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type ShareBatchArgs struct {
+	Out     io.Writer
+	Path    string
+	Notify  bool
+	Message string
+}
+
+// Applies the grants of a csv file with the columns fileId, type, role,
+// email or domain and an optional expiration. A header line starting
+// with fileId and lines starting with # are skipped. All grants are
+// attempted, failed grants are reported
+func (self *Drive) ShareBatch(args ShareBatchArgs) error {
+	f, err := os.Open(args.Path)
+	if err != nil {
+		return fmt.Errorf("Failed to open file: %s", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %s", args.Path, err)
+	}
+
+	if len(records) > 0 && strings.EqualFold(records[0][0], "fileId") {
+		records = records[1:]
+	}
+
+	now := time.Now()
+	failed := 0
+
+	for i, record := range records {
+		fileId, permission, err := parseShareRecord(record, now)
+		if err == nil {
+			err = self.createPermission(fileId, permission, args.Notify, args.Message)
+		}
+
+		if err != nil {
+			fmt.Fprintf(args.Out, "Grant %d failed: %s\n", i+1, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(args.Out, "Granted %s permission on %s to %s\n", permission.Role, fileId, permissionTarget(permission))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d grants failed", failed, len(records))
+	}
+
+	return nil
+}
+
+func parseShareRecord(record []string, now time.Time) (string, *drive.Permission, error) {
+	if len(record) < 3 || len(record) > 5 {
+		return "", nil, fmt.Errorf("Expected fileId,type,role[,email or domain[,expires]], got %d columns", len(record))
+	}
+
+	fileId, shareType, role := record[0], record[1], record[2]
+
+	value := ""
+	if len(record) > 3 {
+		value = record[3]
+	}
+
+	expires := ""
+	if len(record) > 4 {
+		expires = record[4]
+	}
+
+	expirationTime, err := parseExpiration(expires, now)
+	if err != nil {
+		return "", nil, err
+	}
+
+	permission := &drive.Permission{
+		Role:           role,
+		Type:           shareType,
+		ExpirationTime: expirationTime,
+	}
+
+	switch shareType {
+	case "user", "group":
+		permission.EmailAddress = value
+	case "domain":
+		permission.Domain = value
+	case "anyone":
+	default:
+		return "", nil, fmt.Errorf("Invalid type '%s', must be user, group, domain or anyone", shareType)
+	}
+
+	return fileId, permission, nil
+}
+
+func permissionTarget(p *drive.Permission) string {
+	if p.EmailAddress != "" {
+		return p.EmailAddress
+	}
+	if p.Domain != "" {
+		return p.Domain
+	}
+	return p.Type
+}
+
+type ShareCopyArgs struct {
+	Out     io.Writer
+	FromId  string
+	ToIds   []string
+	Notify  bool
+	Message string
+}
+
+// Copies the permissions of a file to other files, owner and inherited
+// permissions are not copied. All grants are attempted, failed grants are reported
+func (self *Drive) ShareCopy(args ShareCopyArgs) error {
+	permissions, err := self.listAllPermissions(args.FromId, false)
+	if err != nil {
+		return err
+	}
+
+	var copyable []*drive.Permission
+	for _, p := range permissions {
+		if p.Role == "owner" || isInherited(p) {
+			continue
+		}
+
+		copyable = append(copyable, &drive.Permission{
+			Role:               p.Role,
+			Type:               p.Type,
+			EmailAddress:       p.EmailAddress,
+			Domain:             p.Domain,
+			AllowFileDiscovery: p.AllowFileDiscovery,
+			ExpirationTime:     p.ExpirationTime,
+		})
+	}
+
+	failed := 0
+
+	for _, toId := range args.ToIds {
+		copied := 0
+
+		for _, p := range copyable {
+			err = self.createPermission(toId, p, args.Notify, args.Message)
+			if err != nil {
+				fmt.Fprintf(args.Out, "Grant of %s to %s failed: %s\n", permissionTarget(p), toId, err)
+				failed++
+				continue
+			}
+			copied++
+		}
+
+		fmt.Fprintf(args.Out, "Copied %d of %d permissions to %s\n", copied, len(copyable), toId)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d grants failed", failed, len(copyable)*len(args.ToIds))
+	}
+
+	return nil
+}
+
+// Permissions inherited from a parent folder or shared drive
+func isInherited(p *drive.Permission) bool {
+	if len(p.PermissionDetails) == 0 {
+		return false
+	}
+
+	for _, d := range p.PermissionDetails {
+		if !d.Inherited {
+			return false
+		}
+	}
+	return true
+}
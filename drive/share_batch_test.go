@@ -0,0 +1,78 @@
+package drive
+
+import (
+	"google.golang.org/api/drive/v3"
+	"testing"
+	"time"
+)
+
+func TestParseShareRecord(t *testing.T) {
+	now := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		record  []string
+		fileId  string
+		want    drive.Permission
+		wantErr bool
+	}{
+		{
+			[]string{"id1", "user", "reader", "me@example.com"},
+			"id1",
+			drive.Permission{Type: "user", Role: "reader", EmailAddress: "me@example.com"},
+			false,
+		},
+		{
+			[]string{"id2", "group", "writer", "team@example.com", "7d"},
+			"id2",
+			drive.Permission{Type: "group", Role: "writer", EmailAddress: "team@example.com", ExpirationTime: "2016-01-09T15:04:05Z"},
+			false,
+		},
+		{
+			[]string{"id3", "domain", "reader", "example.com"},
+			"id3",
+			drive.Permission{Type: "domain", Role: "reader", Domain: "example.com"},
+			false,
+		},
+		{
+			[]string{"id4", "anyone", "reader"},
+			"id4",
+			drive.Permission{Type: "anyone", Role: "reader"},
+			false,
+		},
+		{[]string{"id5", "user"}, "", drive.Permission{}, true},
+		{[]string{"id6", "user", "reader", "me@example.com", "7d", "extra"}, "", drive.Permission{}, true},
+		{[]string{"id7", "robot", "reader", "me@example.com"}, "", drive.Permission{}, true},
+		{[]string{"id8", "user", "reader", "me@example.com", "yesterday"}, "", drive.Permission{}, true},
+	}
+
+	for _, c := range cases {
+		fileId, permission, err := parseShareRecord(c.record, now)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseShareRecord(%q) = %+v, want error", c.record, permission)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseShareRecord(%q) failed: %s", c.record, err)
+			continue
+		}
+
+		if fileId != c.fileId {
+			t.Errorf("parseShareRecord(%q) gave file id %q, want %q", c.record, fileId, c.fileId)
+		}
+
+		if !equalPermission(permission, &c.want) {
+			t.Errorf("parseShareRecord(%q) = %+v, want %+v", c.record, permission, c.want)
+		}
+	}
+}
+
+func equalPermission(a, b *drive.Permission) bool {
+	return a.Type == b.Type &&
+		a.Role == b.Role &&
+		a.EmailAddress == b.EmailAddress &&
+		a.Domain == b.Domain &&
+		a.ExpirationTime == b.ExpirationTime
+}
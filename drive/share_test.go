@@ -0,0 +1,53 @@
+package drive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiration(t *testing.T) {
+	now := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	// A date lasts until the end of that day
+	endOfDay := time.Date(2016, 2, 2, 0, 0, 0, 0, time.Local).UTC().Format(time.RFC3339)
+
+	cases := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"12h", "2016-01-03T03:04:05Z", false},
+		{"7d", "2016-01-09T15:04:05Z", false},
+		{"2w", "2016-01-16T15:04:05Z", false},
+		{"2016-02-01T10:00:00Z", "2016-02-01T10:00:00Z", false},
+		{"2016-02-01T10:00:00+02:00", "2016-02-01T08:00:00Z", false},
+		{"2016-02-01", endOfDay, false},
+		{"2015-12-01", "", true},
+		{"0d", "", true},
+		{"-1d", "", true},
+		{"xd", "", true},
+		{"7y", "", true},
+		{"2015-12-31T10:00:00Z", "", true},
+		{"2016-01-02T15:04:05Z", "", true},
+	}
+
+	for _, c := range cases {
+		got, err := parseExpiration(c.in, now)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseExpiration(%q) = %q, want error", c.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseExpiration(%q) failed: %s", c.in, err)
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("parseExpiration(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
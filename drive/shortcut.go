@@ -0,0 +1,88 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+)
+
+const ShortcutMimeType = "application/vnd.google-apps.shortcut"
+
+func isShortcut(f *drive.File) bool {
+	return f.MimeType == ShortcutMimeType
+}
+
+func shortcutTargetId(f *drive.File) string {
+	if f.ShortcutDetails == nil {
+		return ""
+	}
+	return f.ShortcutDetails.TargetId
+}
+
+func (self *Drive) createShortcut(name, targetId string, parents []string) (*drive.File, error) {
+	dstFile := &drive.File{
+		Name:            name,
+		MimeType:        ShortcutMimeType,
+		Parents:         parents,
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetId},
+	}
+
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Fields("id", "name").Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create shortcut: %s", err)
+	}
+
+	return f, nil
+}
+
+// Returns the file the shortcut points to with the given fields
+func (self *Drive) shortcutTarget(f *drive.File, fields ...googleapi.Field) (*drive.File, error) {
+	targetId := shortcutTargetId(f)
+	if targetId == "" {
+		return nil, fmt.Errorf("Shortcut '%s' has no target", f.Name)
+	}
+
+	target, err := self.service.Files.Get(targetId).SupportsAllDrives(true).Fields(fields...).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get target of shortcut '%s': %s", f.Name, err)
+	}
+
+	return target, nil
+}
+
+type CreateShortcutArgs struct {
+	Out      io.Writer
+	TargetId string
+	ParentId string
+	Name     string
+}
+
+func (self *Drive) CreateShortcut(args CreateShortcutArgs) error {
+	targetId, err := self.resolveId(args.TargetId)
+	if err != nil {
+		return err
+	}
+
+	parentId, err := self.resolveId(args.ParentId)
+	if err != nil {
+		return err
+	}
+
+	name := args.Name
+	if name == "" {
+		target, err := self.service.Files.Get(targetId).SupportsAllDrives(true).Fields("id", "name").Do()
+		if err != nil {
+			return fmt.Errorf("Failed to get file: %s", err)
+		}
+		name = target.Name
+	}
+
+	f, err := self.createShortcut(name, targetId, []string{parentId})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(args.Out, "Shortcut %s created for %s\n", f.Id, targetId)
+	return nil
+}
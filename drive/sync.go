@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -41,7 +42,61 @@ const (
 	KeepLargest
 )
 
-func (self *Drive) prepareSyncFiles(localPath string, root *drive.File, cmp FileComparer) (*syncFiles, error) {
+// DeleteLimit caps the number of extraneous files a sync is allowed to delete,
+// either as an absolute count or as a percentage of the files on the
+// destination side. The zero value imposes no limit.
+type DeleteLimit struct {
+	enabled bool
+	value   float64
+	percent bool
+}
+
+func ParseDeleteLimit(s string) (DeleteLimit, error) {
+	if s == "" {
+		return DeleteLimit{}, nil
+	}
+
+	percent := strings.HasSuffix(s, "%")
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || value < 0 || (percent && value > 100) {
+		return DeleteLimit{}, fmt.Errorf("Invalid delete limit '%s', expected a number or a percentage, i.e. 10 or 5%%", s)
+	}
+
+	if !percent && value != float64(int64(value)) {
+		return DeleteLimit{}, fmt.Errorf("Invalid delete limit '%s', count must be a whole number", s)
+	}
+
+	return DeleteLimit{enabled: true, value: value, percent: percent}, nil
+}
+
+func (self DeleteLimit) String() string {
+	if self.percent {
+		return strconv.FormatFloat(self.value, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(self.value, 'f', -1, 64)
+}
+
+func (self DeleteLimit) exceeded(count, total int) bool {
+	if !self.enabled {
+		return false
+	}
+
+	if self.percent {
+		return total > 0 && float64(count)*100 > self.value*float64(total)
+	}
+
+	return float64(count) > self.value
+}
+
+func checkDeleteLimit(limit DeleteLimit, count, total int) error {
+	if !limit.exceeded(count, total) {
+		return nil
+	}
+
+	return fmt.Errorf("Refusing to delete %d of %d files, this exceeds the delete limit of %s. Verify the sync paths, or raise the limit with --max-delete", count, total, limit)
+}
+
+func (self *Drive) prepareSyncFiles(localPath string, root *drive.File, cmp FileComparer, ignorePatterns []string, c *Cipher) (*syncFiles, error) {
 	localCh := make(chan struct {
 		files []*LocalFile
 		err   error
@@ -52,7 +107,7 @@ func (self *Drive) prepareSyncFiles(localPath string, root *drive.File, cmp File
 	})
 
 	go func() {
-		files, err := prepareLocalFiles(localPath)
+		files, err := prepareLocalFiles(localPath, ignorePatterns)
 		localCh <- struct {
 			files []*LocalFile
 			err   error
@@ -60,7 +115,7 @@ func (self *Drive) prepareSyncFiles(localPath string, root *drive.File, cmp File
 	}()
 
 	go func() {
-		files, err := self.prepareRemoteFiles(root, "")
+		files, err := self.prepareRemoteFiles(root, "", c)
 		remoteCh <- struct {
 			files []*RemoteFile
 			err   error
@@ -77,16 +132,22 @@ func (self *Drive) prepareSyncFiles(localPath string, root *drive.File, cmp File
 		return nil, remote.err
 	}
 
+	if c == nil {
+		if err := checkUnencryptedFiles(remote.files); err != nil {
+			return nil, err
+		}
+	}
+
 	return &syncFiles{
 		root:    &RemoteFile{file: root},
 		local:   local.files,
-		remote:  remote.files,
+		remote:  filterIgnoredRemoteFiles(remote.files, ignorePatterns),
 		compare: cmp,
 	}, nil
 }
 
 func (self *Drive) isSyncFile(id string) (bool, error) {
-	f, err := self.service.Files.Get(id).Fields("appProperties").Do()
+	f, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("appProperties").Do()
 	if err != nil {
 		return false, fmt.Errorf("Failed to get file: %s", err)
 	}
@@ -95,7 +156,7 @@ func (self *Drive) isSyncFile(id string) (bool, error) {
 	return ok, nil
 }
 
-func prepareLocalFiles(root string) ([]*LocalFile, error) {
+func prepareLocalFiles(root string, ignorePatterns []string) ([]*LocalFile, error) {
 	var files []*LocalFile
 
 	// Get absolute root path
@@ -105,7 +166,7 @@ func prepareLocalFiles(root string) ([]*LocalFile, error) {
 	}
 
 	// Prepare ignorer
-	shouldIgnore, err := prepareIgnorer(filepath.Join(absRootPath, DefaultIgnoreFile))
+	shouldIgnore, err := prepareIgnorer(filepath.Join(absRootPath, DefaultIgnoreFile), ignorePatterns)
 	if err != nil {
 		return nil, err
 	}
@@ -152,11 +213,11 @@ func prepareLocalFiles(root string) ([]*LocalFile, error) {
 	return files, err
 }
 
-func (self *Drive) prepareRemoteFiles(rootDir *drive.File, sortOrder string) ([]*RemoteFile, error) {
+func (self *Drive) prepareRemoteFiles(rootDir *drive.File, sortOrder string, c *Cipher) ([]*RemoteFile, error) {
 	// Find all files which has rootDir as root
 	listArgs := listAllFilesArgs{
 		query:     fmt.Sprintf("appProperties has {key='syncRootId' and value='%s'}", rootDir.Id),
-		fields:    []googleapi.Field{"nextPageToken", "files(id,name,parents,md5Checksum,mimeType,size,modifiedTime)"},
+		fields:    []googleapi.Field{"nextPageToken", "files(id,name,parents,md5Checksum,mimeType,size,modifiedTime,appProperties)"},
 		sortOrder: sortOrder,
 	}
 	files, err := self.listAllFiles(listArgs)
@@ -164,6 +225,15 @@ func (self *Drive) prepareRemoteFiles(rootDir *drive.File, sortOrder string) ([]
 		return nil, fmt.Errorf("Failed listing files: %s", err)
 	}
 
+	// Replace encrypted names with their plaintext before building paths
+	if c != nil {
+		for _, f := range files {
+			if f.Name, err = plainName(f, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if err := checkFiles(files); err != nil {
 		return nil, err
 	}
@@ -278,6 +348,16 @@ func checkFiles(files []*drive.File) error {
 	return nil
 }
 
+func checkUnencryptedFiles(files []*RemoteFile) error {
+	for _, rf := range files {
+		if isEncrypted(rf.file) || hasEncryptedName(rf.file) {
+			return fmt.Errorf("Sync root contains encrypted files, use --key-file to sync it")
+		}
+	}
+
+	return nil
+}
+
 type LocalFile struct {
 	absPath string
 	relPath string
@@ -321,7 +401,19 @@ func (self RemoteFile) Md5() string {
 	return self.file.Md5Checksum
 }
 
+// HasPlainMd5 returns true if the plaintext content has the given md5, the
+// keyed checksum of encrypted files is stored in appProperties at upload time
+func (self RemoteFile) HasPlainMd5(md5 string, c *Cipher) bool {
+	if isEncrypted(self.file) {
+		return c != nil && self.file.AppProperties[PlainChecksumProperty] == c.PlainChecksum(md5)
+	}
+	return self.file.Md5Checksum == md5
+}
+
 func (self RemoteFile) Size() int64 {
+	if isEncrypted(self.file) {
+		return plainSize(self.file.Size)
+	}
 	return self.file.Size
 }
 
@@ -588,16 +680,19 @@ func (self byRemotePath) Less(i, j int) bool {
 
 type ignoreFunc func(string) bool
 
-func prepareIgnorer(path string) (ignoreFunc, error) {
+func prepareIgnorer(path string, patterns []string) (ignoreFunc, error) {
 	acceptAll := func(string) bool {
 		return false
 	}
 
 	if !fileExists(path) {
-		return acceptAll, nil
+		if len(patterns) == 0 {
+			return acceptAll, nil
+		}
+		return ignore.CompileIgnoreLines(patterns...).MatchesPath, nil
 	}
 
-	ignorer, err := ignore.CompileIgnoreFile(path)
+	ignorer, err := ignore.CompileIgnoreFileAndLines(path, patterns...)
 	if err != nil {
 		return acceptAll, fmt.Errorf("Failed to prepare ignorer: %s", err)
 	}
@@ -605,6 +700,25 @@ func prepareIgnorer(path string) (ignoreFunc, error) {
 	return ignorer.MatchesPath, nil
 }
 
+// Extra ignore patterns applies to both sides of the sync,
+// unlike the ignore file which only exists locally
+func filterIgnoredRemoteFiles(files []*RemoteFile, patterns []string) []*RemoteFile {
+	if len(patterns) == 0 {
+		return files
+	}
+
+	ignorer := ignore.CompileIgnoreLines(patterns...)
+
+	var filtered []*RemoteFile
+	for _, rf := range files {
+		if !ignorer.MatchesPath(rf.relPath) {
+			filtered = append(filtered, rf)
+		}
+	}
+
+	return filtered
+}
+
 func formatConflicts(conflicts []*changedFile, out io.Writer) {
 	w := new(tabwriter.Writer)
 	w.Init(out, 0, 0, 3, ' ', 0)
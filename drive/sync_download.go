@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -19,12 +21,22 @@ type DownloadSyncArgs struct {
 	Path             string
 	DryRun           bool
 	DeleteExtraneous bool
+	MaxDelete        DeleteLimit
+	BackupDir        string
 	Timeout          time.Duration
 	Resolution       ConflictResolution
 	Comparer         FileComparer
+	IgnorePatterns   []string
+	Cipher           *Cipher
 }
 
 func (self *Drive) DownloadSync(args DownloadSyncArgs) error {
+	if args.BackupDir != "" {
+		if err := checkBackupDir(args.Path, args.BackupDir); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintln(args.Out, "Starting sync...")
 	started := time.Now()
 
@@ -35,7 +47,7 @@ func (self *Drive) DownloadSync(args DownloadSyncArgs) error {
 	}
 
 	fmt.Fprintln(args.Out, "Collecting file information...")
-	files, err := self.prepareSyncFiles(args.Path, rootDir, args.Comparer)
+	files, err := self.prepareSyncFiles(args.Path, rootDir, args.Comparer, args.IgnorePatterns, args.Cipher)
 	if err != nil {
 		return err
 	}
@@ -53,6 +65,14 @@ func (self *Drive) DownloadSync(args DownloadSyncArgs) error {
 		}
 	}
 
+	// Ensure that we don't delete more files than allowed
+	if args.DeleteExtraneous {
+		extraneousCount := len(files.filterExtraneousLocalFiles())
+		if err := checkDeleteLimit(args.MaxDelete, extraneousCount, len(files.local)); err != nil {
+			return err
+		}
+	}
+
 	// Create missing directories
 	err = self.createMissingLocalDirs(files, args)
 	if err != nil {
@@ -85,7 +105,7 @@ func (self *Drive) DownloadSync(args DownloadSyncArgs) error {
 
 func (self *Drive) getSyncRoot(rootId string) (*drive.File, error) {
 	fields := []googleapi.Field{"id", "name", "mimeType", "appProperties"}
-	f, err := self.service.Files.Get(rootId).Fields(fields...).Do()
+	f, err := self.service.Files.Get(rootId).SupportsAllDrives(true).Fields(fields...).Do()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to find root dir: %s", err)
 	}
@@ -146,7 +166,7 @@ func (self *Drive) downloadMissingFiles(files *syncFiles, args DownloadSyncArgs)
 		}
 		fmt.Fprintf(args.Out, "[%04d/%04d] Downloading %s -> %s\n", i+1, missingCount, rf.relPath, filepath.Join(filepath.Base(args.Path), rf.relPath))
 
-		err = self.downloadRemoteFile(rf.file.Id, absPath, args, 0)
+		err = self.downloadRemoteFile(rf, absPath, args, 0)
 		if err != nil {
 			return err
 		}
@@ -174,7 +194,7 @@ func (self *Drive) downloadChangedFiles(changedFiles []*changedFile, args Downlo
 		}
 		fmt.Fprintf(args.Out, "[%04d/%04d] Downloading %s -> %s\n", i+1, changedCount, cf.remote.relPath, filepath.Join(filepath.Base(args.Path), cf.remote.relPath))
 
-		err = self.downloadRemoteFile(cf.remote.file.Id, absPath, args, 0)
+		err = self.downloadRemoteFile(cf.remote, absPath, args, 0)
 		if err != nil {
 			return err
 		}
@@ -183,7 +203,7 @@ func (self *Drive) downloadChangedFiles(changedFiles []*changedFile, args Downlo
 	return nil
 }
 
-func (self *Drive) downloadRemoteFile(id, fpath string, args DownloadSyncArgs, try int) error {
+func (self *Drive) downloadRemoteFile(rf *RemoteFile, fpath string, args DownloadSyncArgs, try int) error {
 	if args.DryRun {
 		return nil
 	}
@@ -191,12 +211,12 @@ func (self *Drive) downloadRemoteFile(id, fpath string, args DownloadSyncArgs, t
 	// Get timeout reader wrapper and context
 	timeoutReaderWrapper, ctx := getTimeoutReaderWrapperContext(args.Timeout)
 
-	res, err := self.service.Files.Get(id).Context(ctx).Download()
+	res, err := self.service.Files.Get(rf.file.Id).SupportsAllDrives(true).Context(ctx).Download()
 	if err != nil {
 		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
 			exponentialBackoffSleep(try)
 			try++
-			return self.downloadRemoteFile(id, fpath, args, try)
+			return self.downloadRemoteFile(rf, fpath, args, try)
 		} else if isTimeoutError(err) {
 			return fmt.Errorf("Failed to download file: timeout, no data was transferred for %v", args.Timeout)
 		} else {
@@ -213,6 +233,14 @@ func (self *Drive) downloadRemoteFile(id, fpath string, args DownloadSyncArgs, t
 	// Wrap reader in timeout reader
 	reader := timeoutReaderWrapper(progressReader)
 
+	// Decrypt content
+	if isEncrypted(rf.file) {
+		reader, err = args.Cipher.DecryptReader(reader)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Ensure any parent directories exists
 	if err = mkdir(fpath); err != nil {
 		return err
@@ -234,7 +262,7 @@ func (self *Drive) downloadRemoteFile(id, fpath string, args DownloadSyncArgs, t
 		if try < MaxErrorRetries {
 			exponentialBackoffSleep(try)
 			try++
-			return self.downloadRemoteFile(id, fpath, args, try)
+			return self.downloadRemoteFile(rf, fpath, args, try)
 		} else {
 			os.Remove(tmpPath)
 			return fmt.Errorf("Download was interrupted: %s", err)
@@ -244,6 +272,14 @@ func (self *Drive) downloadRemoteFile(id, fpath string, args DownloadSyncArgs, t
 	// Close file
 	outFile.Close()
 
+	// Move the file we are about to replace to the backup dir
+	if args.BackupDir != "" && fileExists(fpath) {
+		if err = backupLocalFile(fpath, backupPath(args.BackupDir, rf.relPath)); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+
 	// Rename tmp file to proper filename
 	return os.Rename(tmpPath, fpath)
 }
@@ -260,6 +296,22 @@ func (self *Drive) deleteExtraneousLocalFiles(files *syncFiles, args DownloadSyn
 	sort.Sort(sort.Reverse(byLocalPathLength(extraneousFiles)))
 
 	for i, lf := range extraneousFiles {
+		// Directories are emptied by the time we get to them, so there is nothing to back up
+		if args.BackupDir != "" && !lf.info.IsDir() {
+			backupPath := backupPath(args.BackupDir, lf.relPath)
+			fmt.Fprintf(args.Out, "[%04d/%04d] Moving %s -> %s\n", i+1, extraneousCount, lf.absPath, backupPath)
+
+			if args.DryRun {
+				continue
+			}
+
+			err := backupLocalFile(lf.absPath, backupPath)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		fmt.Fprintf(args.Out, "[%04d/%04d] Deleting %s\n", i+1, extraneousCount, lf.absPath)
 
 		if args.DryRun {
@@ -275,6 +327,95 @@ func (self *Drive) deleteExtraneousLocalFiles(files *syncFiles, args DownloadSyn
 	return nil
 }
 
+// Returns the path in the backup dir for the file, a counter is added
+// to the name when an earlier backup of the same file exists
+func backupPath(backupDir, relPath string) string {
+	backupPath := filepath.Join(backupDir, relPath)
+	ext := filepath.Ext(backupPath)
+	if ext == filepath.Base(backupPath) {
+		// Dot files like .bashrc have no extension
+		ext = ""
+	}
+	base := strings.TrimSuffix(backupPath, ext)
+
+	for i := 1; fileExists(backupPath); i++ {
+		backupPath = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+
+	return backupPath
+}
+
+func backupLocalFile(absPath, backupPath string) error {
+	// Ensure any parent directories exists
+	if err := mkdir(backupPath); err != nil {
+		return fmt.Errorf("Failed to create backup directory: %s", err)
+	}
+
+	// Rename fails when the backup dir is on another device, copy the file instead
+	err := os.Rename(absPath, backupPath)
+	if isCrossDeviceError(err) {
+		err = copyAndRemove(absPath, backupPath)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to move local file to backup dir: %s", err)
+	}
+
+	return nil
+}
+
+func copyAndRemove(srcPath, dstPath string) error {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	err = copyFileTo(dst, srcPath)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dstPath)
+		return err
+	}
+
+	// Keep the modification time of the original file
+	if err = os.Chtimes(dstPath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	return os.Remove(srcPath)
+}
+
+func isCrossDeviceError(err error) bool {
+	linkErr, ok := err.(*os.LinkError)
+	return ok && linkErr.Err == syscall.EXDEV
+}
+
+func checkBackupDir(syncPath, backupDir string) error {
+	absSyncPath, err := filepath.Abs(syncPath)
+	if err != nil {
+		return fmt.Errorf("Failed to determine local absolute path: %s", err)
+	}
+
+	absBackupDir, err := filepath.Abs(backupDir)
+	if err != nil {
+		return fmt.Errorf("Failed to determine backup dir absolute path: %s", err)
+	}
+
+	// Files in the backup dir would otherwise be picked up by the sync itself
+	relPath, err := filepath.Rel(absSyncPath, absBackupDir)
+	if err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+		return fmt.Errorf("Backup dir can not be inside the sync directory")
+	}
+
+	return nil
+}
+
 func checkLocalConflict(cf *changedFile, resolution ConflictResolution) (bool, string) {
 	// No conflict unless local file was last modified
 	if cf.compareModTime() != LocalLastModified {
@@ -324,5 +465,5 @@ func ensureNoLocalModifications(files []*changedFile) error {
 
 	buffer := bytes.NewBufferString("")
 	formatConflicts(conflicts, buffer)
-	return fmt.Errorf(buffer.String())
+	return fmt.Errorf("%s", buffer.String())
 }
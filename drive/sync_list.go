@@ -42,7 +42,7 @@ func (self *Drive) ListRecursiveSync(args ListRecursiveSyncArgs) error {
 		return err
 	}
 
-	files, err := self.prepareRemoteFiles(rootDir, args.SortOrder)
+	files, err := self.prepareRemoteFiles(rootDir, args.SortOrder, nil)
 	if err != nil {
 		return err
 	}
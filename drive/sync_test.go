@@ -0,0 +1,140 @@
+package drive
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestParseDeleteLimit(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    DeleteLimit
+		wantErr bool
+	}{
+		{"", DeleteLimit{}, false},
+		{"0", DeleteLimit{enabled: true, value: 0}, false},
+		{"10", DeleteLimit{enabled: true, value: 10}, false},
+		{"5%", DeleteLimit{enabled: true, value: 5, percent: true}, false},
+		{"2.5%", DeleteLimit{enabled: true, value: 2.5, percent: true}, false},
+		{"100%", DeleteLimit{enabled: true, value: 100, percent: true}, false},
+		{"101%", DeleteLimit{}, true},
+		{"2.5", DeleteLimit{}, true},
+		{"-1", DeleteLimit{}, true},
+		{"ten", DeleteLimit{}, true},
+		{"%", DeleteLimit{}, true},
+	}
+
+	for _, c := range cases {
+		got, err := ParseDeleteLimit(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseDeleteLimit(%q) = %+v, want error", c.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseDeleteLimit(%q) failed: %s", c.in, err)
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("ParseDeleteLimit(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCheckDeleteLimit(t *testing.T) {
+	cases := []struct {
+		limit   string
+		count   int
+		total   int
+		wantErr bool
+	}{
+		// No limit
+		{"", 1000, 1000, false},
+
+		// Counts
+		{"0", 0, 10, false},
+		{"0", 1, 10, true},
+		{"10", 10, 100, false},
+		{"10", 11, 100, true},
+
+		// Percentages
+		{"10%", 10, 100, false},
+		{"10%", 11, 100, true},
+		{"50%", 1, 2, false},
+		{"50%", 2, 3, true},
+		{"10%", 0, 0, false},
+	}
+
+	for _, c := range cases {
+		limit, err := ParseDeleteLimit(c.limit)
+		if err != nil {
+			t.Fatalf("ParseDeleteLimit(%q) failed: %s", c.limit, err)
+		}
+
+		err = checkDeleteLimit(limit, c.count, c.total)
+		if (err != nil) != c.wantErr {
+			t.Errorf("checkDeleteLimit(%s, %d, %d) = %v, want error: %t", c.limit, c.count, c.total, err, c.wantErr)
+		}
+	}
+}
+
+func TestBackupPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gdrive-backup")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	existing := []string{"a.txt", "a.1.txt", "sub/b", ".bashrc", "c.tar.gz"}
+	for _, name := range existing {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := mkdir(path); err != nil {
+			t.Fatalf("mkdir failed: %s", err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+			t.Fatalf("WriteFile failed: %s", err)
+		}
+	}
+
+	cases := []struct {
+		relPath string
+		want    string
+	}{
+		{"new.txt", "new.txt"},
+		{"a.txt", "a.2.txt"},
+		{"sub/b", "sub/b.1"},
+		{".bashrc", ".bashrc.1"},
+		{"c.tar.gz", "c.tar.1.gz"},
+	}
+
+	for _, c := range cases {
+		want := filepath.Join(dir, filepath.FromSlash(c.want))
+		if got := backupPath(dir, filepath.FromSlash(c.relPath)); got != want {
+			t.Errorf("backupPath(%q) = %q, want %q", c.relPath, got, want)
+		}
+	}
+}
+
+func TestIsCrossDeviceError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.EXDEV}, true},
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.EACCES}, false},
+		{&os.PathError{Op: "open", Path: "a", Err: syscall.EXDEV}, false},
+	}
+
+	for _, c := range cases {
+		if got := isCrossDeviceError(c.err); got != c.want {
+			t.Errorf("isCrossDeviceError(%v) = %t, want %t", c.err, got, c.want)
+		}
+	}
+}
@@ -19,10 +19,15 @@ type UploadSyncArgs struct {
 	RootId           string
 	DryRun           bool
 	DeleteExtraneous bool
+	MaxDelete        DeleteLimit
 	ChunkSize        int64
 	Timeout          time.Duration
 	Resolution       ConflictResolution
 	Comparer         FileComparer
+	IgnorePatterns   []string
+	Cipher           *Cipher
+	EncryptName      bool
+	CreateParents    bool
 }
 
 func (self *Drive) UploadSync(args UploadSyncArgs) error {
@@ -33,6 +38,13 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 	fmt.Fprintln(args.Out, "Starting sync...")
 	started := time.Now()
 
+	// Resolve root given as a path
+	rootId, err := self.resolveParentId(args.RootId, args.CreateParents)
+	if err != nil {
+		return err
+	}
+	args.RootId = rootId
+
 	// Create root directory if it does not exist
 	rootDir, err := self.prepareSyncRoot(args)
 	if err != nil {
@@ -40,7 +52,7 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 	}
 
 	fmt.Fprintln(args.Out, "Collecting local and remote file information...")
-	files, err := self.prepareSyncFiles(args.Path, rootDir, args.Comparer)
+	files, err := self.prepareSyncFiles(args.Path, rootDir, args.Comparer, args.IgnorePatterns, args.Cipher)
 	if err != nil {
 		return err
 	}
@@ -53,7 +65,7 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 
 	// Ensure that there is enough free space on drive
 	if ok, msg := self.checkRemoteFreeSpace(missingFiles, changedFiles); !ok {
-		return fmt.Errorf(msg)
+		return fmt.Errorf("%s", msg)
 	}
 
 	// Ensure that we don't overwrite any remote changes
@@ -64,6 +76,14 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 		}
 	}
 
+	// Ensure that we don't delete more files than allowed
+	if args.DeleteExtraneous {
+		extraneousCount := len(files.filterExtraneousRemoteFiles())
+		if err := checkDeleteLimit(args.MaxDelete, extraneousCount, len(files.remote)); err != nil {
+			return err
+		}
+	}
+
 	// Create missing directories
 	files, err = self.createMissingRemoteDirs(files, args)
 	if err != nil {
@@ -82,7 +102,7 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 		return err
 	}
 
-	// Delete extraneous files on drive
+	// Move extraneous files on drive to trash
 	if args.DeleteExtraneous {
 		err = self.deleteExtraneousRemoteFiles(files, args)
 		if err != nil {
@@ -96,7 +116,7 @@ func (self *Drive) UploadSync(args UploadSyncArgs) error {
 
 func (self *Drive) prepareSyncRoot(args UploadSyncArgs) (*drive.File, error) {
 	fields := []googleapi.Field{"id", "name", "mimeType", "appProperties"}
-	f, err := self.service.Files.Get(args.RootId).Fields(fields...).Do()
+	f, err := self.service.Files.Get(args.RootId).SupportsAllDrives(true).Fields(fields...).Do()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to find root dir: %s", err)
 	}
@@ -128,7 +148,7 @@ func (self *Drive) prepareSyncRoot(args UploadSyncArgs) (*drive.File, error) {
 		AppProperties: map[string]string{"sync": "true", "syncRoot": "true"},
 	}
 
-	f, err = self.service.Files.Update(f.Id, dstFile).Fields(fields...).Do()
+	f, err = self.service.Files.Update(f.Id, dstFile).SupportsAllDrives(true).Fields(fields...).Do()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to update root directory: %s", err)
 	}
@@ -157,11 +177,13 @@ func (self *Drive) createMissingRemoteDirs(files *syncFiles, args UploadSyncArgs
 		fmt.Fprintf(args.Out, "[%04d/%04d] Creating directory %s\n", i+1, missingCount, filepath.Join(files.root.file.Name, lf.relPath))
 
 		f, err := self.createMissingRemoteDir(createMissingRemoteDirArgs{
-			name:     lf.info.Name(),
-			parentId: parent.file.Id,
-			rootId:   args.RootId,
-			dryRun:   args.DryRun,
-			try:      0,
+			name:        lf.info.Name(),
+			parentId:    parent.file.Id,
+			rootId:      args.RootId,
+			dryRun:      args.DryRun,
+			cipher:      args.Cipher,
+			encryptName: args.EncryptName,
+			try:         0,
 		})
 		if err != nil {
 			return nil, err
@@ -177,11 +199,13 @@ func (self *Drive) createMissingRemoteDirs(files *syncFiles, args UploadSyncArgs
 }
 
 type createMissingRemoteDirArgs struct {
-	name     string
-	parentId string
-	rootId   string
-	dryRun   bool
-	try      int
+	name        string
+	parentId    string
+	rootId      string
+	dryRun      bool
+	cipher      *Cipher
+	encryptName bool
+	try         int
 }
 
 func (self *Drive) uploadMissingFiles(missingFiles []*LocalFile, files *syncFiles, args UploadSyncArgs) error {
@@ -245,9 +269,9 @@ func (self *Drive) deleteExtraneousRemoteFiles(files *syncFiles, args UploadSync
 	sort.Sort(sort.Reverse(byRemotePathLength(extraneousFiles)))
 
 	for i, rf := range extraneousFiles {
-		fmt.Fprintf(args.Out, "[%04d/%04d] Deleting %s\n", i+1, extraneousCount, filepath.Join(files.root.file.Name, rf.relPath))
+		fmt.Fprintf(args.Out, "[%04d/%04d] Trashing %s\n", i+1, extraneousCount, filepath.Join(files.root.file.Name, rf.relPath))
 
-		err := self.deleteRemoteFile(rf, args, 0)
+		err := self.trashRemoteFile(rf, args, 0)
 		if err != nil {
 			return err
 		}
@@ -264,11 +288,17 @@ func (self *Drive) createMissingRemoteDir(args createMissingRemoteDirArgs) (*dri
 		AppProperties: map[string]string{"sync": "true", "syncRootId": args.rootId},
 	}
 
+	// Encrypt directory name
+	if args.cipher != nil && args.encryptName {
+		dstFile.Name = args.cipher.EncryptName(args.name)
+		dstFile.AppProperties[EncryptedNameProperty] = "true"
+	}
+
 	if args.dryRun {
 		return dstFile, nil
 	}
 
-	f, err := self.service.Files.Create(dstFile).Do()
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Do()
 	if err != nil {
 		if isBackendOrRateLimitError(err) && args.try < MaxErrorRetries {
 			exponentialBackoffSleep(args.try)
@@ -306,12 +336,22 @@ func (self *Drive) uploadMissingFile(parentId string, lf *LocalFile, args Upload
 	chunkSize := googleapi.ChunkSize(int(args.ChunkSize))
 
 	// Wrap file in progress reader
-	progressReader := getProgressReader(srcFile, args.Progress, lf.info.Size())
+	var reader io.Reader = getProgressReader(srcFile, args.Progress, lf.info.Size())
+
+	// Encrypt content
+	var encryptReader *EncryptReader
+	if args.Cipher != nil {
+		encryptReader, err = encryptUpload(dstFile, reader, args.Cipher, args.EncryptName)
+		if err != nil {
+			return err
+		}
+		reader = encryptReader
+	}
 
 	// Wrap reader in timeout reader
-	reader, ctx := getTimeoutReaderContext(progressReader, args.Timeout)
+	reader, ctx := getTimeoutReaderContext(reader, args.Timeout)
 
-	_, err = self.service.Files.Create(dstFile).Fields("id", "name", "size", "md5Checksum").Context(ctx).Media(reader, chunkSize).Do()
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Fields("id", "name", "size", "md5Checksum").Context(ctx).Media(reader, chunkSize).Do()
 	if err != nil {
 		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
 			exponentialBackoffSleep(try)
@@ -324,6 +364,10 @@ func (self *Drive) uploadMissingFile(parentId string, lf *LocalFile, args Upload
 		}
 	}
 
+	if encryptReader != nil {
+		return self.setPlainChecksum(f.Id, encryptReader.PlainChecksum())
+	}
+
 	return nil
 }
 
@@ -347,12 +391,22 @@ func (self *Drive) updateChangedFile(cf *changedFile, args UploadSyncArgs, try i
 	chunkSize := googleapi.ChunkSize(int(args.ChunkSize))
 
 	// Wrap file in progress reader
-	progressReader := getProgressReader(srcFile, args.Progress, cf.local.info.Size())
+	var reader io.Reader = getProgressReader(srcFile, args.Progress, cf.local.info.Size())
+
+	// Encrypt content, the existing name is kept as is
+	var encryptReader *EncryptReader
+	if args.Cipher != nil {
+		encryptReader, err = encryptUpload(dstFile, reader, args.Cipher, false)
+		if err != nil {
+			return err
+		}
+		reader = encryptReader
+	}
 
 	// Wrap reader in timeout reader
-	reader, ctx := getTimeoutReaderContext(progressReader, args.Timeout)
+	reader, ctx := getTimeoutReaderContext(reader, args.Timeout)
 
-	_, err = self.service.Files.Update(cf.remote.file.Id, dstFile).Context(ctx).Media(reader, chunkSize).Do()
+	_, err = self.service.Files.Update(cf.remote.file.Id, dstFile).SupportsAllDrives(true).Context(ctx).Media(reader, chunkSize).Do()
 	if err != nil {
 		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
 			exponentialBackoffSleep(try)
@@ -365,22 +419,28 @@ func (self *Drive) updateChangedFile(cf *changedFile, args UploadSyncArgs, try i
 		}
 	}
 
+	if encryptReader != nil {
+		return self.setPlainChecksum(cf.remote.file.Id, encryptReader.PlainChecksum())
+	}
+
 	return nil
 }
 
-func (self *Drive) deleteRemoteFile(rf *RemoteFile, args UploadSyncArgs, try int) error {
+func (self *Drive) trashRemoteFile(rf *RemoteFile, args UploadSyncArgs, try int) error {
 	if args.DryRun {
 		return nil
 	}
 
-	err := self.service.Files.Delete(rf.file.Id).Do()
+	dstFile := &drive.File{Trashed: true}
+
+	_, err := self.service.Files.Update(rf.file.Id, dstFile).SupportsAllDrives(true).Fields("id").Do()
 	if err != nil {
 		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
 			exponentialBackoffSleep(try)
 			try++
-			return self.deleteRemoteFile(rf, args, try)
+			return self.trashRemoteFile(rf, args, try)
 		} else {
-			return fmt.Errorf("Failed to delete file: %s", err)
+			return fmt.Errorf("Failed to trash file: %s", err)
 		}
 	}
 
@@ -389,9 +449,9 @@ func (self *Drive) deleteRemoteFile(rf *RemoteFile, args UploadSyncArgs, try int
 
 func (self *Drive) dirIsEmpty(id string) (bool, error) {
 	query := fmt.Sprintf("'%s' in parents", id)
-	fileList, err := self.service.Files.List().Q(query).Do()
+	fileList, err := self.filesList().Q(query).Do()
 	if err != nil {
-		return false, fmt.Errorf("Empty dir check failed: ", err)
+		return false, fmt.Errorf("Empty dir check failed: %s", err)
 	}
 
 	return len(fileList.Files) == 0, nil
@@ -446,7 +506,7 @@ func ensureNoRemoteModifications(files []*changedFile) error {
 
 	buffer := bytes.NewBufferString("")
 	formatConflicts(conflicts, buffer)
-	return fmt.Errorf(buffer.String())
+	return fmt.Errorf("%s", buffer.String())
 }
 
 func (self *Drive) checkRemoteFreeSpace(missingFiles []*LocalFile, changedFiles []*changedFile) (bool, string) {
@@ -0,0 +1,209 @@
+package drive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"os"
+	"strings"
+)
+
+// Status of a file in the ownership transfer report
+const (
+	TransferTransferred = "transferred"
+	TransferAlready     = "already"
+	TransferSkipped     = "skipped"
+	TransferFailed      = "failed"
+)
+
+type TransferOwnershipArgs struct {
+	Out        io.Writer
+	Id         string
+	Email      string
+	Recursive  bool
+	ReportPath string
+}
+
+type transferResult struct {
+	file   *drive.File
+	status string
+	err    error
+}
+
+// Makes the user owner of the directory and, when recursive, of all files and
+// directories below it that are owned by me. Files that already are owned by
+// the user are left as is, so an interrupted transfer is resumed by running it again
+func (self *Drive) TransferOwnership(args TransferOwnershipArgs) error {
+	if self.sharedDriveId != "" {
+		return fmt.Errorf("Files in shared drives are owned by the shared drive, ownership can not be given away")
+	}
+
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	files, err := self.transferFiles(id, args.Recursive)
+	if err != nil {
+		return err
+	}
+
+	// The report is written as the files are transferred,
+	// so it is complete up to the point of an interruption
+	var report *transferReport
+	if args.ReportPath != "" {
+		report, err = newTransferReport(args.ReportPath)
+		if err != nil {
+			return err
+		}
+		defer report.close()
+	}
+
+	counts := map[string]int{}
+
+	for _, f := range files {
+		result := &transferResult{file: f, status: transferStatus(f, args.Email)}
+
+		if result.status == "" {
+			result.err = self.transferFile(f.Id, args.Email, 0)
+			if result.err != nil {
+				result.status = TransferFailed
+				fmt.Fprintf(args.Out, "Failed to transfer %s (%s): %s\n", f.Name, f.Id, result.err)
+			} else {
+				result.status = TransferTransferred
+				fmt.Fprintf(args.Out, "Transferred %s (%s)\n", f.Name, f.Id)
+			}
+		}
+
+		counts[result.status]++
+
+		if report != nil {
+			if err = report.write(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(args.Out, "Transferred %d, already owned by %s %d, skipped %d owned by others, failed %d\n",
+		counts[TransferTransferred], args.Email, counts[TransferAlready], counts[TransferSkipped], counts[TransferFailed])
+
+	if counts[TransferFailed] > 0 {
+		return fmt.Errorf("Failed to transfer %d files", counts[TransferFailed])
+	}
+	return nil
+}
+
+// Returns the directory followed by the files below it when recursive
+func (self *Drive) transferFiles(id string, recursive bool) ([]*drive.File, error) {
+	fields := []googleapi.Field{"id", "name", "mimeType", "owners(me,emailAddress)"}
+
+	root, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields(fields...).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	files := []*drive.File{root}
+	if !recursive || !isDir(root) {
+		return files, nil
+	}
+
+	folderIds, err := self.listFolderIds(id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &findQuery{terms: []string{"trashed = false"}}
+
+	for _, batch := range batchIds(folderIds, maxParentsPerQuery) {
+		listArgs := listAllFilesArgs{
+			query:  query.String(batch),
+			fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType,owners(me,emailAddress))"},
+		}
+		result, err := self.listAllFiles(listArgs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list files: %s", err)
+		}
+		files = append(files, result...)
+	}
+
+	return files, nil
+}
+
+// Returns the status of files that should not be transferred, or an empty string
+func transferStatus(f *drive.File, email string) string {
+	for _, owner := range f.Owners {
+		if owner.Me {
+			return ""
+		}
+	}
+
+	for _, owner := range f.Owners {
+		if strings.EqualFold(owner.EmailAddress, email) {
+			return TransferAlready
+		}
+	}
+
+	return TransferSkipped
+}
+
+func (self *Drive) transferFile(fileId, email string, try int) error {
+	permission := &drive.Permission{
+		Type:         "user",
+		Role:         "owner",
+		EmailAddress: email,
+	}
+
+	_, err := self.service.Permissions.Create(fileId, permission).TransferOwnership(true).Fields("id").Do()
+	if err != nil {
+		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
+			exponentialBackoffSleep(try)
+			try++
+			return self.transferFile(fileId, email, try)
+		}
+		return err
+	}
+
+	return nil
+}
+
+type transferReport struct {
+	file *os.File
+	csv  *csv.Writer
+}
+
+func newTransferReport(path string) (*transferReport, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create report: %s", err)
+	}
+
+	report := &transferReport{file: f, csv: csv.NewWriter(f)}
+	if err = report.writeRecord("id", "name", "status", "error"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return report, nil
+}
+
+func (self *transferReport) write(r *transferResult) error {
+	errMsg := ""
+	if r.err != nil {
+		errMsg = r.err.Error()
+	}
+	return self.writeRecord(r.file.Id, r.file.Name, r.status, errMsg)
+}
+
+func (self *transferReport) writeRecord(record ...string) error {
+	self.csv.Write(record)
+	self.csv.Flush()
+	if err := self.csv.Error(); err != nil {
+		return fmt.Errorf("Failed to write report: %s", err)
+	}
+	return nil
+}
+
+func (self *transferReport) close() {
+	self.file.Close()
+}
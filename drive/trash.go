@@ -0,0 +1,97 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+)
+
+type ListTrashArgs struct {
+	Out         io.Writer
+	MaxFiles    int64
+	NameWidth   int64
+	SortOrder   string
+	SkipHeader  bool
+	SizeInBytes bool
+}
+
+func (self *Drive) ListTrash(args ListTrashArgs) error {
+	listArgs := listAllFilesArgs{
+		query:     "trashed = true",
+		fields:    []googleapi.Field{"nextPageToken", "files(id,name,md5Checksum,mimeType,size,createdTime)"},
+		sortOrder: args.SortOrder,
+		maxFiles:  args.MaxFiles,
+	}
+	files, err := self.listAllFiles(listArgs)
+	if err != nil {
+		return fmt.Errorf("Failed to list trashed files: %s", err)
+	}
+
+	PrintFileList(PrintFileListArgs{
+		Out:         args.Out,
+		Files:       files,
+		NameWidth:   int(args.NameWidth),
+		SkipHeader:  args.SkipHeader,
+		SizeInBytes: args.SizeInBytes,
+	})
+
+	return nil
+}
+
+type RestoreTrashArgs struct {
+	Out io.Writer
+	Id  string
+}
+
+func (self *Drive) RestoreTrash(args RestoreTrashArgs) error {
+	f, err := self.service.Files.Get(args.Id).SupportsAllDrives(true).Fields("name", "trashed").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	if !f.Trashed {
+		return fmt.Errorf("'%s' is not in the trash", f.Name)
+	}
+
+	// Trashed is omitted from the request unless it is forced
+	dstFile := &drive.File{
+		Trashed:         false,
+		ForceSendFields: []string{"Trashed"},
+	}
+
+	_, err = self.service.Files.Update(args.Id, dstFile).SupportsAllDrives(true).Fields("id").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to restore file: %s", err)
+	}
+
+	fmt.Fprintf(args.Out, "Restored '%s'\n", f.Name)
+	return nil
+}
+
+type EmptyTrashArgs struct {
+	Out   io.Writer
+	Force bool
+}
+
+func (self *Drive) EmptyTrash(args EmptyTrashArgs) error {
+	if !args.Force {
+		return fmt.Errorf("Emptying the trash permanently deletes all trashed files, use --force to continue")
+	}
+
+	call := self.service.Files.EmptyTrash()
+	if self.sharedDriveId != "" {
+		call = call.DriveId(self.sharedDriveId)
+	}
+
+	err := call.Do()
+	if self.sharedDriveId != "" && isInsufficientPermissionsError(err) {
+		return fmt.Errorf("Failed to empty trash, this requires the organizer role on the shared drive: %s", err)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to empty trash: %s", err)
+	}
+
+	fmt.Fprintln(args.Out, "Trash emptied")
+	return nil
+}
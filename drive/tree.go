@@ -0,0 +1,338 @@
+package drive
+
+import (
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultListParallel = 8
+
+type TreeArgs struct {
+	Out         io.Writer
+	Id          string
+	Depth       int
+	DirsOnly    bool
+	Sizes       bool
+	SortOrder   string
+	Json        bool
+	SizeInBytes bool
+	Parallel    int
+}
+
+func (self *Drive) Tree(args TreeArgs) error {
+	if err := checkTreeSortOrder(args.SortOrder); err != nil {
+		return err
+	}
+
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	root, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType", "size").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	if !isDir(root) {
+		return fmt.Errorf("'%s' is not a directory", root.Name)
+	}
+
+	// Folder totals include everything below the folder,
+	// so the whole tree is fetched when sizes are shown
+	maxDepth := args.Depth
+	if args.Sizes {
+		maxDepth = 0
+	}
+
+	tree, err := self.fetchTree(root, maxDepth, args.Parallel)
+	if err != nil {
+		return err
+	}
+
+	// Sort by name first so files with multiple parents are
+	// always counted in the same directory, like du does
+	tree.sort("name")
+	tree.calculateTotals(map[string]bool{root.Id: true})
+	tree.sort(args.SortOrder)
+
+	if args.Json {
+		return printJson(args.Out, toTreeJson(tree, 0, args))
+	}
+
+	printTree(args.Out, tree, args)
+	return nil
+}
+
+func checkTreeSortOrder(order string) error {
+	switch order {
+	case "", "name", "size", "count":
+		return nil
+	}
+	return fmt.Errorf("Invalid sort order '%s', must be name, size or count", order)
+}
+
+type treeNode struct {
+	file     *drive.File
+	children []*treeNode
+
+	// Totals of all files below a directory
+	size      int64
+	fileCount int
+	dirCount  int
+}
+
+type treeFetcher struct {
+	drive    *Drive
+	maxDepth int
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	mutex    sync.Mutex
+	err      error
+}
+
+// Lists the content of the directory and all its subdirectories, the
+// directories are listed in parallel. A max depth of 0 means no limit
+func (self *Drive) fetchTree(root *drive.File, maxDepth, parallel int) (*treeNode, error) {
+	if parallel < 1 {
+		parallel = DefaultListParallel
+	}
+
+	fetcher := &treeFetcher{
+		drive:    self,
+		maxDepth: maxDepth,
+		sem:      make(chan struct{}, parallel),
+	}
+
+	node := &treeNode{file: root}
+	fetcher.fetch(node, 1)
+	fetcher.wg.Wait()
+
+	if fetcher.err != nil {
+		return nil, fetcher.err
+	}
+
+	return node, nil
+}
+
+func (self *treeFetcher) fetch(node *treeNode, depth int) {
+	self.wg.Add(1)
+
+	go func() {
+		defer self.wg.Done()
+
+		self.sem <- struct{}{}
+		files, err := self.drive.listChildren(node.file.Id, 0)
+		<-self.sem
+
+		if err != nil {
+			self.setError(err)
+			return
+		}
+
+		node.children = make([]*treeNode, 0, len(files))
+		for _, f := range files {
+			child := &treeNode{file: f}
+			node.children = append(node.children, child)
+
+			if isDir(f) && (self.maxDepth == 0 || depth < self.maxDepth) {
+				self.fetch(child, depth+1)
+			}
+		}
+	}()
+}
+
+func (self *treeFetcher) setError(err error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	if self.err == nil {
+		self.err = err
+	}
+}
+
+func (self *Drive) listChildren(parentId string, try int) ([]*drive.File, error) {
+	listArgs := listAllFilesArgs{
+		query:  fmt.Sprintf("'%s' in parents and trashed = false", parentId),
+		fields: []googleapi.Field{"nextPageToken", "files(id,name,mimeType,size,md5Checksum,modifiedTime,parents,appProperties)"},
+	}
+	files, err := self.listAllFiles(listArgs)
+	if err != nil {
+		if isBackendOrRateLimitError(err) && try < MaxErrorRetries {
+			exponentialBackoffSleep(try)
+			try++
+			return self.listChildren(parentId, try)
+		}
+		return nil, fmt.Errorf("Failed listing files: %s", err)
+	}
+
+	return files, nil
+}
+
+// Files and directories already seen are skipped,
+// so files with multiple parents are only counted once
+func (self *treeNode) calculateTotals(seen map[string]bool) {
+	self.size, self.fileCount, self.dirCount = 0, 0, 0
+
+	for _, child := range self.children {
+		if seen[child.file.Id] {
+			continue
+		}
+		seen[child.file.Id] = true
+
+		if !isDir(child.file) {
+			self.size += child.file.Size
+			self.fileCount++
+			continue
+		}
+
+		child.calculateTotals(seen)
+		self.size += child.size
+		self.fileCount += child.fileCount
+		self.dirCount += child.dirCount + 1
+	}
+}
+
+// Sorts the children of all directories, directories are listed before files
+func (self *treeNode) sort(order string) {
+	sort.SliceStable(self.children, func(i, j int) bool {
+		a, b := self.children[i], self.children[j]
+
+		if isDir(a.file) != isDir(b.file) {
+			return isDir(a.file)
+		}
+
+		switch order {
+		case "size":
+			if a.totalSize() != b.totalSize() {
+				return a.totalSize() > b.totalSize()
+			}
+		case "count":
+			if a.fileCount != b.fileCount {
+				return a.fileCount > b.fileCount
+			}
+		}
+
+		return strings.ToLower(a.file.Name) < strings.ToLower(b.file.Name)
+	})
+
+	for _, child := range self.children {
+		child.sort(order)
+	}
+}
+
+func (self *treeNode) totalSize() int64 {
+	if isDir(self.file) {
+		return self.size
+	}
+	return self.file.Size
+}
+
+func printTree(out io.Writer, root *treeNode, args TreeArgs) {
+	fmt.Fprintln(out, treeLabel(root, args))
+	printTreeChildren(out, root, "", 1, args)
+
+	if args.DirsOnly {
+		fmt.Fprintf(out, "\n%d directories\n", root.dirCount)
+		return
+	}
+	fmt.Fprintf(out, "\n%d directories, %d files\n", root.dirCount, root.fileCount)
+}
+
+func printTreeChildren(out io.Writer, node *treeNode, prefix string, depth int, args TreeArgs) {
+	children := visibleTreeChildren(node, args)
+
+	for i, child := range children {
+		last := i == len(children)-1
+
+		branch, indent := "|-- ", "|   "
+		if last {
+			branch, indent = "`-- ", "    "
+		}
+
+		fmt.Fprintf(out, "%s%s%s\n", prefix, branch, treeLabel(child, args))
+
+		if isDir(child.file) && (args.Depth == 0 || depth < args.Depth) {
+			printTreeChildren(out, child, prefix+indent, depth+1, args)
+		}
+	}
+}
+
+func visibleTreeChildren(node *treeNode, args TreeArgs) []*treeNode {
+	if !args.DirsOnly {
+		return node.children
+	}
+
+	var dirs []*treeNode
+	for _, child := range node.children {
+		if isDir(child.file) {
+			dirs = append(dirs, child)
+		}
+	}
+	return dirs
+}
+
+func treeLabel(node *treeNode, args TreeArgs) string {
+	if !isDir(node.file) {
+		if args.Sizes && node.file.Size > 0 {
+			return fmt.Sprintf("%s [%s]", node.file.Name, formatSize(node.file.Size, args.SizeInBytes))
+		}
+		return node.file.Name
+	}
+
+	if !args.Sizes {
+		return node.file.Name + "/"
+	}
+
+	size := formatSize(node.size, args.SizeInBytes)
+	if size == "" {
+		size = "0 B"
+	}
+	return fmt.Sprintf("%s/ [%d files, %s]", node.file.Name, node.fileCount, size)
+}
+
+type treeJson struct {
+	Id       string      `json:"id"`
+	Name     string      `json:"name"`
+	MimeType string      `json:"mimeType"`
+	Size     int64       `json:"size"`
+	Files    *int        `json:"files,omitempty"`
+	Dirs     *int        `json:"dirs,omitempty"`
+	Children []*treeJson `json:"children,omitempty"`
+}
+
+func toTreeJson(node *treeNode, depth int, args TreeArgs) *treeJson {
+	if !isDir(node.file) {
+		return &treeJson{
+			Id:       node.file.Id,
+			Name:     node.file.Name,
+			MimeType: node.file.MimeType,
+			Size:     node.file.Size,
+		}
+	}
+
+	t := &treeJson{
+		Id:       node.file.Id,
+		Name:     node.file.Name,
+		MimeType: node.file.MimeType,
+	}
+
+	if args.Sizes {
+		t.Size = node.size
+		t.Files = &node.fileCount
+		t.Dirs = &node.dirCount
+	}
+
+	if args.Depth == 0 || depth < args.Depth {
+		for _, child := range visibleTreeChildren(node, args) {
+			t.Children = append(t.Children, toTreeJson(child, depth+1, args))
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,38 @@
+package drive
+
+import (
+	"google.golang.org/api/drive/v3"
+	"testing"
+)
+
+func TestTreeTotalsMatchDu(t *testing.T) {
+	dir := func(id string, children ...*treeNode) *treeNode {
+		return &treeNode{file: &drive.File{Id: id, Name: id, MimeType: DirectoryMimeType}, children: children}
+	}
+	file := func(id string, size int64) *treeNode {
+		return &treeNode{file: &drive.File{Id: id, Name: id, Size: size, Md5Checksum: "md5"}}
+	}
+
+	// The shared file and directory have two parents and are listed twice
+	shared := file("shared", 100)
+	sharedDir := dir("sharedDir", file("c", 10))
+	root := dir("root",
+		dir("a", shared, file("a1", 1), sharedDir),
+		dir("b", shared, file("b1", 2), sharedDir),
+		file("d", 1000),
+	)
+
+	root.sort("name")
+	root.calculateTotals(map[string]bool{root.file.Id: true})
+
+	var entries []*DuEntry
+	du := collectDu(root, "root", 0, map[string]bool{root.file.Id: true}, 0, &entries)
+
+	if root.size != 1113 || root.fileCount != 5 || root.dirCount != 3 {
+		t.Errorf("Tree totals = %d bytes, %d files, %d dirs, want 1113 bytes, 5 files, 3 dirs", root.size, root.fileCount, root.dirCount)
+	}
+
+	if root.size != du.Size || root.fileCount != du.Files {
+		t.Errorf("Tree totals = %d bytes, %d files, du = %d bytes, %d files", root.size, root.fileCount, du.Size, du.Files)
+	}
+}
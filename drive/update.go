@@ -22,6 +22,7 @@ type UpdateArgs struct {
 	Recursive   bool
 	ChunkSize   int64
 	Timeout     time.Duration
+	Properties  map[string]string
 }
 
 func (self *Drive) Update(args UpdateArgs) error {
@@ -33,7 +34,7 @@ func (self *Drive) Update(args UpdateArgs) error {
 	defer srcFile.Close()
 
 	// Instantiate empty drive file
-	dstFile := &drive.File{Description: args.Description}
+	dstFile := &drive.File{Description: args.Description, Properties: args.Properties}
 
 	// Use provided file name or use filename
 	if args.Name == "" {
@@ -64,7 +65,7 @@ func (self *Drive) Update(args UpdateArgs) error {
 	fmt.Fprintf(args.Out, "Uploading %s\n", args.Path)
 	started := time.Now()
 
-	f, err := self.service.Files.Update(args.Id, dstFile).Fields("id", "name", "size").Context(ctx).Media(reader, chunkSize).Do()
+	f, err := self.service.Files.Update(args.Id, dstFile).SupportsAllDrives(true).Fields("id", "name", "size").Context(ctx).Media(reader, chunkSize).Do()
 	if err != nil {
 		if isTimeoutError(err) {
 			return fmt.Errorf("Failed to upload file: timeout, no data was transferred for %v", args.Timeout)
@@ -12,18 +12,23 @@ import (
 )
 
 type UploadArgs struct {
-	Out         io.Writer
-	Progress    io.Writer
-	Path        string
-	Name        string
-	Description string
-	Parents     []string
-	Mime        string
-	Recursive   bool
-	Share       bool
-	Delete      bool
-	ChunkSize   int64
-	Timeout     time.Duration
+	Out           io.Writer
+	Progress      io.Writer
+	Path          string
+	Name          string
+	Description   string
+	Parents       []string
+	Mime          string
+	Recursive     bool
+	Share         bool
+	Delete        bool
+	ChunkSize     int64
+	Timeout       time.Duration
+	Cipher        *Cipher
+	EncryptName   bool
+	Archive       string
+	CreateParents bool
+	Properties    map[string]string
 }
 
 func (self *Drive) Upload(args UploadArgs) error {
@@ -31,6 +36,17 @@ func (self *Drive) Upload(args UploadArgs) error {
 		return fmt.Errorf("Chunk size is to big, max chunk size for this computer is %d", intMax()-1)
 	}
 
+	// Resolve parents given as paths
+	parents := make([]string, len(args.Parents))
+	for i, parent := range args.Parents {
+		id, err := self.resolveParentId(parent, args.CreateParents)
+		if err != nil {
+			return err
+		}
+		parents[i] = id
+	}
+	args.Parents = parents
+
 	// Ensure that none of the parents are sync dirs
 	for _, parent := range args.Parents {
 		isSyncDir, err := self.isSyncFile(parent)
@@ -43,6 +59,10 @@ func (self *Drive) Upload(args UploadArgs) error {
 		}
 	}
 
+	if args.Archive != "" {
+		return self.uploadArchive(args)
+	}
+
 	if args.Recursive {
 		return self.uploadRecursive(args)
 	}
@@ -109,13 +129,23 @@ func (self *Drive) uploadDirectory(args UploadArgs) error {
 	defer srcFile.Close()
 
 	fmt.Fprintf(args.Out, "Creating directory %s\n", srcFileInfo.Name())
-	// Make directory on drive
-	f, err := self.mkdir(MkdirArgs{
+
+	mkdirArgs := MkdirArgs{
 		Out:         args.Out,
 		Name:        srcFileInfo.Name(),
 		Parents:     args.Parents,
 		Description: args.Description,
-	})
+		Properties:  args.Properties,
+	}
+
+	// Encrypt directory name
+	if args.Cipher != nil && args.EncryptName {
+		mkdirArgs.Name = args.Cipher.EncryptName(mkdirArgs.Name)
+		mkdirArgs.AppProperties = map[string]string{EncryptedNameProperty: "true"}
+	}
+
+	// Make directory on drive
+	f, err := self.mkdir(mkdirArgs)
 	if err != nil {
 		return err
 	}
@@ -153,7 +183,7 @@ func (self *Drive) uploadFile(args UploadArgs) (*drive.File, int64, error) {
 	defer srcFile.Close()
 
 	// Instantiate empty drive file
-	dstFile := &drive.File{Description: args.Description}
+	dstFile := &drive.File{Description: args.Description, Properties: args.Properties}
 
 	// Use provided file name or use filename
 	if args.Name == "" {
@@ -170,21 +200,31 @@ func (self *Drive) uploadFile(args UploadArgs) (*drive.File, int64, error) {
 	}
 
 	// Set parent folders
-	dstFile.Parents = args.Parents
+	dstFile.Parents = self.defaultParents(args.Parents)
 
 	// Chunk size option
 	chunkSize := googleapi.ChunkSize(int(args.ChunkSize))
 
 	// Wrap file in progress reader
-	progressReader := getProgressReader(srcFile, args.Progress, srcFileInfo.Size())
+	var reader io.Reader = getProgressReader(srcFile, args.Progress, srcFileInfo.Size())
+
+	// Encrypt content
+	var encryptReader *EncryptReader
+	if args.Cipher != nil {
+		encryptReader, err = encryptUpload(dstFile, reader, args.Cipher, args.EncryptName)
+		if err != nil {
+			return nil, 0, err
+		}
+		reader = encryptReader
+	}
 
 	// Wrap reader in timeout reader
-	reader, ctx := getTimeoutReaderContext(progressReader, args.Timeout)
+	reader, ctx := getTimeoutReaderContext(reader, args.Timeout)
 
 	fmt.Fprintf(args.Out, "Uploading %s\n", args.Path)
 	started := time.Now()
 
-	f, err := self.service.Files.Create(dstFile).Fields("id", "name", "size", "md5Checksum", "webContentLink").Context(ctx).Media(reader, chunkSize).Do()
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Fields("id", "name", "size", "md5Checksum", "webContentLink").Context(ctx).Media(reader, chunkSize).Do()
 	if err != nil {
 		if isTimeoutError(err) {
 			return nil, 0, fmt.Errorf("Failed to upload file: timeout, no data was transferred for %v", args.Timeout)
@@ -195,6 +235,13 @@ func (self *Drive) uploadFile(args UploadArgs) (*drive.File, int64, error) {
 	// Calculate average upload rate
 	rate := calcRate(f.Size, started, time.Now())
 
+	// Save checksum of the plaintext, the md5 checksum of drive is of the encrypted content
+	if encryptReader != nil {
+		if err = self.setPlainChecksum(f.Id, encryptReader.PlainChecksum()); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return f, rate, nil
 }
 
@@ -209,6 +256,9 @@ type UploadStreamArgs struct {
 	ChunkSize   int64
 	Progress    io.Writer
 	Timeout     time.Duration
+	Cipher      *Cipher
+	EncryptName bool
+	Properties  map[string]string
 }
 
 func (self *Drive) UploadStream(args UploadStreamArgs) error {
@@ -217,7 +267,7 @@ func (self *Drive) UploadStream(args UploadStreamArgs) error {
 	}
 
 	// Instantiate empty drive file
-	dstFile := &drive.File{Name: args.Name, Description: args.Description}
+	dstFile := &drive.File{Name: args.Name, Description: args.Description, Properties: args.Properties}
 
 	// Set mime type if provided
 	if args.Mime != "" {
@@ -225,21 +275,33 @@ func (self *Drive) UploadStream(args UploadStreamArgs) error {
 	}
 
 	// Set parent folders
-	dstFile.Parents = args.Parents
+	dstFile.Parents = self.defaultParents(args.Parents)
 
 	// Chunk size option
 	chunkSize := googleapi.ChunkSize(int(args.ChunkSize))
 
 	// Wrap file in progress reader
-	progressReader := getProgressReader(args.In, args.Progress, 0)
+	var reader io.Reader = getProgressReader(args.In, args.Progress, 0)
+
+	fmt.Fprintf(args.Out, "Uploading %s\n", dstFile.Name)
+
+	// Encrypt content
+	var encryptReader *EncryptReader
+	if args.Cipher != nil {
+		var err error
+		encryptReader, err = encryptUpload(dstFile, reader, args.Cipher, args.EncryptName)
+		if err != nil {
+			return err
+		}
+		reader = encryptReader
+	}
 
 	// Wrap reader in timeout reader
-	reader, ctx := getTimeoutReaderContext(progressReader, args.Timeout)
+	reader, ctx := getTimeoutReaderContext(reader, args.Timeout)
 
-	fmt.Fprintf(args.Out, "Uploading %s\n", dstFile.Name)
 	started := time.Now()
 
-	f, err := self.service.Files.Create(dstFile).Fields("id", "name", "size", "webContentLink").Context(ctx).Media(reader, chunkSize).Do()
+	f, err := self.service.Files.Create(dstFile).SupportsAllDrives(true).Fields("id", "name", "size", "webContentLink").Context(ctx).Media(reader, chunkSize).Do()
 	if err != nil {
 		if isTimeoutError(err) {
 			return fmt.Errorf("Failed to upload file: timeout, no data was transferred for %v", args.Timeout)
@@ -250,6 +312,13 @@ func (self *Drive) UploadStream(args UploadStreamArgs) error {
 	// Calculate average upload rate
 	rate := calcRate(f.Size, started, time.Now())
 
+	// Save checksum of the plaintext, the md5 checksum of drive is of the encrypted content
+	if encryptReader != nil {
+		if err = self.setPlainChecksum(f.Id, encryptReader.PlainChecksum()); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintf(args.Out, "Uploaded %s at %s/s, total %s\n", f.Id, formatSize(rate, false), formatSize(f.Size, false))
 	if args.Share {
 		err = self.shareAnyoneReader(f.Id)
@@ -261,3 +330,26 @@ func (self *Drive) UploadStream(args UploadStreamArgs) error {
 	}
 	return nil
 }
+
+// Prepares the drive file for encrypted content and wraps the reader
+func encryptUpload(dstFile *drive.File, r io.Reader, c *Cipher, encryptName bool) (*EncryptReader, error) {
+	encryptReader, err := c.EncryptReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if encryptName {
+		dstFile.Name = c.EncryptName(dstFile.Name)
+	}
+
+	dstFile.MimeType = EncryptedMimeType
+
+	if dstFile.AppProperties == nil {
+		dstFile.AppProperties = map[string]string{}
+	}
+	for key, value := range encryptedAppProperties(encryptName) {
+		dstFile.AppProperties[key] = value
+	}
+
+	return encryptReader, nil
+}
@@ -1,7 +1,9 @@
 package drive
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -11,6 +13,20 @@ import (
 	"unicode/utf8"
 )
 
+const (
+	FormatTable = "table"
+	FormatCsv   = "csv"
+	FormatJson  = "json"
+)
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case FormatTable, FormatCsv, FormatJson:
+		return nil
+	}
+	return fmt.Errorf("Invalid format '%s', must be %s, %s or %s", format, FormatTable, FormatCsv, FormatJson)
+}
+
 type kv struct {
 	key   string
 	value string
@@ -71,6 +87,57 @@ func formatDatetime(iso string) string {
 	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", year, month, day, hour, min, sec)
 }
 
+// Parses a date (2006-01-02) in local time or an RFC3339 datetime
+func parseDatetime(value string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid date '%s', expected 2006-01-02 or 2006-01-02T15:04:05Z07:00", value)
+	}
+	return t, nil
+}
+
+// Parses a size like 100M or 1.5GB into bytes, the units are
+// powers of 1000 like formatSize, or powers of 1024 when given as KiB, MiB, etc
+func parseSize(value string) (int64, error) {
+	units := map[string]float64{
+		"":    1,
+		"B":   1,
+		"K":   1e3,
+		"KB":  1e3,
+		"M":   1e6,
+		"MB":  1e6,
+		"G":   1e9,
+		"GB":  1e9,
+		"T":   1e12,
+		"TB":  1e12,
+		"KIB": 1 << 10,
+		"MIB": 1 << 20,
+		"GIB": 1 << 30,
+		"TIB": 1 << 40,
+	}
+
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(value)
+	}
+
+	number, err := strconv.ParseFloat(value[:i], 64)
+	unit, ok := units[strings.ToUpper(strings.TrimSpace(value[i:]))]
+	if err != nil || !ok {
+		return 0, fmt.Errorf("Invalid size '%s', expected i.e. 500K, 100M or 1.5G", value)
+	}
+
+	return int64(number * unit), nil
+}
+
 // Truncates string to given max length, and inserts ellipsis into
 // the middle of the string to signify that the string has been truncated
 func truncateString(str string, maxRunes int) string {
@@ -115,6 +182,11 @@ func truncateString(str string, maxRunes int) string {
 	return truncated
 }
 
+// Escapes a value for use inside single quotes in a drive query
+func escapeQuery(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	if err == nil {
@@ -167,3 +239,14 @@ func openFile(path string) (*os.File, os.FileInfo, error) {
 
 	return f, info, nil
 }
+
+func printJson(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("Failed to write json: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package drive
+
+import (
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"500", 500, false},
+		{"500B", 500, false},
+		{"500K", 500 * 1000, false},
+		{"500kb", 500 * 1000, false},
+		{"100M", 100 * 1000 * 1000, false},
+		{"1.5G", 1500 * 1000 * 1000, false},
+		{"2T", 2 * 1000 * 1000 * 1000 * 1000, false},
+		{"1KiB", 1024, false},
+		{"1.5MiB", 1536 * 1024, false},
+		{"1GiB", 1 << 30, false},
+		{" 10 MB ", 10 * 1000 * 1000, false},
+		{"", 0, true},
+		{"M", 0, true},
+		{"10X", 0, true},
+		{"1.2.3M", 0, true},
+		{"-1M", 0, true},
+	}
+
+	for _, c := range cases {
+		got, err := parseSize(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseSize(%q) = %d, want error", c.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseSize(%q) failed: %s", c.in, err)
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("parseSize(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
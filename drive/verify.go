@@ -0,0 +1,237 @@
+package drive
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+type VerifyArgs struct {
+	Out      io.Writer
+	Path     string
+	Id       string
+	Cipher   *Cipher
+	Parallel int
+}
+
+// Compares the content of a local directory to a remote directory,
+// returns an error if any differences are found
+func (self *Drive) Verify(args VerifyArgs) error {
+	absPath, err := filepath.Abs(args.Path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("Failed stat file: %s", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", args.Path)
+	}
+
+	id, err := self.resolveId(args.Id)
+	if err != nil {
+		return err
+	}
+
+	root, err := self.service.Files.Get(id).SupportsAllDrives(true).Fields("id", "name", "mimeType").Do()
+	if err != nil {
+		return fmt.Errorf("Failed to get file: %s", err)
+	}
+
+	if !isDir(root) {
+		return fmt.Errorf("'%s' is not a directory", root.Name)
+	}
+
+	fmt.Fprintf(args.Out, "Listing remote files in '%s'\n", root.Name)
+
+	tree, err := self.fetchTree(root, 0, args.Parallel)
+	if err != nil {
+		return err
+	}
+
+	remote := &remoteFileSet{
+		files:      map[string]RemoteFile{},
+		duplicates: map[string]bool{},
+	}
+
+	err = remote.collect(tree, "", args.Cipher)
+	if err != nil {
+		return err
+	}
+
+	var differences []*verifyDifference
+	for relPath := range remote.duplicates {
+		differences = append(differences, &verifyDifference{"Duplicate", relPath})
+	}
+
+	localFiles, err := collectLocalFiles(absPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(args.Out, "Comparing %d local files with %d remote files\n", len(localFiles), len(remote.files))
+
+	for relPath, size := range localFiles {
+		if remote.duplicates[relPath] {
+			continue
+		}
+
+		remoteFile, found := remote.files[relPath]
+		if !found {
+			differences = append(differences, &verifyDifference{"Missing", relPath})
+			continue
+		}
+
+		if remoteFile.Size() != size {
+			differences = append(differences, &verifyDifference{"Mismatch", relPath})
+			continue
+		}
+
+		md5, err := localMd5(filepath.Join(absPath, relPath))
+		if err != nil {
+			return err
+		}
+
+		if !remoteFile.HasPlainMd5(md5, args.Cipher) {
+			differences = append(differences, &verifyDifference{"Mismatch", relPath})
+		}
+	}
+
+	for relPath := range remote.files {
+		if _, found := localFiles[relPath]; !found {
+			differences = append(differences, &verifyDifference{"Extra", relPath})
+		}
+	}
+
+	if remote.skippedDocs > 0 {
+		fmt.Fprintf(args.Out, "Skipped %d google documents, they have no local counterpart\n", remote.skippedDocs)
+	}
+
+	if len(differences) == 0 {
+		fmt.Fprintf(args.Out, "All %d files are identical\n", len(localFiles))
+		return nil
+	}
+
+	printVerifyDifferences(args.Out, differences)
+
+	return fmt.Errorf("Found %d differences between '%s' and '%s'", len(differences), args.Path, root.Name)
+}
+
+type verifyDifference struct {
+	status string
+	path   string
+}
+
+func printVerifyDifferences(out io.Writer, differences []*verifyDifference) {
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].path < differences[j].path
+	})
+
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "\nStatus\tPath")
+
+	for _, d := range differences {
+		fmt.Fprintf(w, "%s\t%s\n", d.status, d.path)
+	}
+
+	w.Flush()
+}
+
+// The binary files of a remote directory by their relative path
+type remoteFileSet struct {
+	files map[string]RemoteFile
+
+	// Paths used by more than one remote file, they can not be compared
+	duplicates  map[string]bool
+	skippedDocs int
+}
+
+func (self *remoteFileSet) collect(node *treeNode, path string, c *Cipher) error {
+	seen := map[string]bool{}
+
+	for _, child := range node.children {
+		name, err := plainName(child.file, c)
+		if err != nil {
+			return err
+		}
+
+		relPath := filepath.Join(path, name)
+
+		if seen[relPath] {
+			self.duplicates[relPath] = true
+			delete(self.files, relPath)
+			continue
+		}
+		seen[relPath] = true
+
+		if isDir(child.file) {
+			err = self.collect(child, relPath, c)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isBinary(child.file) {
+			self.skippedDocs++
+			continue
+		}
+
+		self.files[relPath] = RemoteFile{relPath: relPath, file: child.file}
+	}
+
+	return nil
+}
+
+// Returns the size of the regular files below the directory by their relative path
+func collectLocalFiles(root string) (map[string]int64, error) {
+	files := map[string]int64{}
+
+	err := filepath.Walk(root, func(absPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, absPath)
+		if err != nil {
+			return err
+		}
+
+		files[relPath] = info.Size()
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list local files: %s", err)
+	}
+
+	return files, nil
+}
+
+func localMd5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to open file: %s", err)
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("Failed to read file: %s", err)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
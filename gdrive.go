@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/prasmussen/gdrive/cli"
+	"github.com/prasmussen/gdrive/drive"
 )
 
 const Name = "gdrive"
@@ -13,10 +14,14 @@ const Version = "2.1.1"
 const DefaultMaxFiles = 30
 const DefaultMaxChanges = 100
 const DefaultNameWidth = 40
+const DefaultCommentWidth = 60
 const DefaultPathWidth = 60
 const DefaultUploadChunkSize = 8 * 1024 * 1024
 const DefaultTimeout = 5 * 60
 const DefaultQuery = "trashed = false and 'me' in owners"
+
+// Files in shared drives are owned by the shared drive, not by me
+const DefaultSharedDriveQuery = "trashed = false"
 const DefaultShareRole = "reader"
 const DefaultShareType = "anyone"
 
@@ -45,8 +50,24 @@ func main() {
 			Patterns:    []string{"--service-account"},
 			Description: "Oauth service account filename, used for server to server communication without user interaction (filename path is relative to config dir)",
 		},
+		cli.StringFlag{
+			Name:        "sharedDrive",
+			Patterns:    []string{"--drive"},
+			Description: "Id or name of a shared drive to use instead of my drive, needed to list and search directories in the shared drive",
+		},
 	}
 
+	// head and tail use -c for the byte count like coreutils,
+	// so the config dir can only be given as --config
+	rangeGlobalFlags := append([]cli.Flag{
+		cli.StringFlag{
+			Name:         "configDir",
+			Patterns:     []string{"--config"},
+			Description:  fmt.Sprintf("Application path, default: %s", DefaultConfigDir),
+			DefaultValue: DefaultConfigDir,
+		},
+	}, globalFlags[1:]...)
+
 	handlers := []*cli.Handler{
 		&cli.Handler{
 			Pattern:     "[global] list [options]",
@@ -99,6 +120,261 @@ func main() {
 				),
 			},
 		},
+		&cli.Handler{
+			Pattern:     "[global] drives list [options]",
+			Description: "List shared drives",
+			Callback:    listDrivesHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives create <name>",
+			Description: "Create shared drive",
+			Callback:    createDriveHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives rename [options] <driveId> <name>",
+			Description: "Rename shared drive",
+			Callback:    renameDriveHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives hide <driveId>",
+			Description: "Hide shared drive from the default view",
+			Callback:    hideDriveHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives unhide <driveId>",
+			Description: "Restore hidden shared drive to the default view",
+			Callback:    unhideDriveHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives delete [options] <driveId>",
+			Description: "Delete empty shared drive",
+			Callback:    deleteDriveHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives members list [options] <driveId>",
+			Description: "List shared drive members",
+			Callback:    listDriveMembersHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives members add [options] <driveId> <email>",
+			Description: "Add shared drive member, the email is a domain for the domain type",
+			Callback:    addDriveMemberHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:         "role",
+						Patterns:     []string{"--role"},
+						Description:  fmt.Sprintf("Member role: organizer/fileOrganizer/writer/commenter/reader, default: %s", DefaultShareRole),
+						DefaultValue: DefaultShareRole,
+					},
+					cli.StringFlag{
+						Name:         "type",
+						Patterns:     []string{"--type"},
+						Description:  "Member type: user/group/domain, default: user",
+						DefaultValue: "user",
+					},
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives members remove [options] <driveId> <member>",
+			Description: "Remove shared drive member by permission id or email",
+			Callback:    removeDriveMemberHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] drives members set-role [options] <driveId> <member> <role>",
+			Description: "Change role of shared drive member given by permission id or email",
+			Callback:    setDriveMemberRoleHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "admin",
+						Patterns:    []string{"--admin"},
+						Description: "Issue the request as a domain administrator",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] tree [options] <fileId>",
+			Description: "Show the directory and its content as a tree",
+			Callback:    treeHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.IntFlag{
+						Name:        "depth",
+						Patterns:    []string{"--depth"},
+						Description: "Max depth of the tree, default: no limit",
+					},
+					cli.BoolFlag{
+						Name:        "dirsOnly",
+						Patterns:    []string{"--dirs-only"},
+						Description: "Only show directories",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "sizes",
+						Patterns:    []string{"--sizes"},
+						Description: "Show sizes, and total size and file count of directories. The whole tree is listed to calculate the totals",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:         "sortOrder",
+						Patterns:     []string{"--order"},
+						Description:  "Sort order of directory content: name, size or count. Directories are listed first",
+						DefaultValue: "name",
+					},
+					cli.BoolFlag{
+						Name:        "json",
+						Patterns:    []string{"--json"},
+						Description: "Print the tree as json",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
+						OmitValue:   true,
+					},
+					cli.IntFlag{
+						Name:         "parallel",
+						Patterns:     []string{"--parallel"},
+						Description:  fmt.Sprintf("Number of directories to list in parallel, default: %d", drive.DefaultListParallel),
+						DefaultValue: drive.DefaultListParallel,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] du [options] <fileId>",
+			Description: "Show disk usage of the directory and its subdirectories",
+			Callback:    duHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.IntFlag{
+						Name:        "depth",
+						Patterns:    []string{"--depth"},
+						Description: "Max depth of subdirectories to show, default: no limit",
+					},
+					cli.IntFlag{
+						Name:        "top",
+						Patterns:    []string{"--top"},
+						Description: "Only show the first n directories after sorting",
+					},
+					cli.StringFlag{
+						Name:         "sortOrder",
+						Patterns:     []string{"--order"},
+						Description:  "Sort order: size, files, docs or path",
+						DefaultValue: "size",
+					},
+					cli.StringFlag{
+						Name:         "format",
+						Patterns:     []string{"--format"},
+						Description:  "Output format: table, csv or json",
+						DefaultValue: drive.FormatTable,
+					},
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
+						OmitValue:   true,
+					},
+					cli.IntFlag{
+						Name:         "parallel",
+						Patterns:     []string{"--parallel"},
+						Description:  fmt.Sprintf("Number of directories to list in parallel, default: %d", drive.DefaultListParallel),
+						DefaultValue: drive.DefaultListParallel,
+					},
+				),
+			},
+		},
 		&cli.Handler{
 			Pattern:     "[global] download [options] <fileId>",
 			Description: "Download file or directory",
@@ -153,6 +429,23 @@ func main() {
 						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
 						DefaultValue: DefaultTimeout,
 					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, used to decrypt encrypted files",
+					},
+					cli.BoolFlag{
+						Name:        "extract",
+						Patterns:    []string{"--extract"},
+						Description: "Extract tar, tar.gz or zip archive to the download path",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "followShortcuts",
+						Patterns:    []string{"--follow-shortcuts"},
+						Description: "Download the targets of shortcuts, shortcuts are skipped by default",
+						OmitValue:   true,
+					},
 				),
 			},
 		},
@@ -192,36 +485,117 @@ func main() {
 						Description: "Hide progress",
 						OmitValue:   true,
 					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, used to decrypt encrypted files",
+					},
 				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] upload [options] <path>",
-			Description: "Upload file or directory",
-			Callback:    uploadHandler,
+			Pattern:     "[global] find [options]",
+			Description: "Find files matching the given filters, and optionally run an action on them",
+			Callback:    findHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "name",
+						Patterns:    []string{"--name"},
+						Description: "File name, may be a glob pattern like '*.jpg'. Matching is case insensitive",
+					},
+					cli.StringFlag{
+						Name:        "mime",
+						Patterns:    []string{"--mime"},
+						Description: "Mime type, i.e. image/jpeg or image/*",
+					},
+					cli.StringFlag{
+						Name:        "type",
+						Patterns:    []string{"--type"},
+						Description: "File type: dir, doc or bin",
+					},
+					cli.StringFlag{
+						Name:        "larger",
+						Patterns:    []string{"--larger"},
+						Description: "Only files larger than the given size, i.e. 500K, 100M or 1.5G",
+					},
+					cli.StringFlag{
+						Name:        "modifiedAfter",
+						Patterns:    []string{"--modified-after"},
+						Description: "Only files modified after the given date, i.e. 2016-01-02",
+					},
+					cli.StringFlag{
+						Name:        "modifiedBefore",
+						Patterns:    []string{"--modified-before"},
+						Description: "Only files modified before the given date, i.e. 2016-01-02",
+					},
+					cli.StringFlag{
+						Name:        "owner",
+						Patterns:    []string{"--owner"},
+						Description: "Email of the owner, or 'me'",
+					},
 					cli.BoolFlag{
-						Name:        "recursive",
-						Patterns:    []string{"-r", "--recursive"},
-						Description: "Upload directory recursively",
+						Name:        "shared",
+						Patterns:    []string{"--shared"},
+						Description: "Only files that are shared",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "starred",
+						Patterns:    []string{"--starred"},
+						Description: "Only starred files",
 						OmitValue:   true,
 					},
 					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Match files with the custom property key=value, can be specified multiple times",
+					},
+					cli.StringFlag{
 						Name:        "parent",
-						Patterns:    []string{"-p", "--parent"},
-						Description: "Parent id, used to upload file to a specific directory, can be specified multiple times to give many parents",
+						Patterns:    []string{"--in"},
+						Description: "Only files in the given directory",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also find files in subdirectories of the --in directory",
+						OmitValue:   true,
 					},
 					cli.StringFlag{
-						Name:        "name",
-						Patterns:    []string{"--name"},
-						Description: "Filename",
+						Name:        "exec",
+						Patterns:    []string{"--exec"},
+						Description: "Command to run for each file, {} is replaced with the file id and {name} with the file name",
+					},
+					cli.BoolFlag{
+						Name:        "delete",
+						Patterns:    []string{"--delete"},
+						Description: "Move the files to trash, requires at least one filter. The files are only listed unless --force is given, files in sync directories are skipped",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "download",
+						Patterns:    []string{"--download"},
+						Description: "Download the files, documents and directories are skipped",
+						OmitValue:   true,
 					},
 					cli.StringFlag{
-						Name:        "description",
-						Patterns:    []string{"--description"},
-						Description: "File description",
+						Name:        "path",
+						Patterns:    []string{"--path"},
+						Description: "Download path",
+					},
+					cli.BoolFlag{
+						Name:        "force",
+						Patterns:    []string{"-f", "--force"},
+						Description: "Overwrite existing files when downloading, or move the files to trash with --delete",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "skip",
+						Patterns:    []string{"-s", "--skip"},
+						Description: "Skip existing files when downloading",
+						OmitValue:   true,
 					},
 					cli.BoolFlag{
 						Name:        "noProgress",
@@ -230,24 +604,171 @@ func main() {
 						OmitValue:   true,
 					},
 					cli.StringFlag{
-						Name:        "mime",
-						Patterns:    []string{"--mime"},
-						Description: "Force mime type",
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, used to decrypt encrypted files",
 					},
 					cli.BoolFlag{
-						Name:        "share",
-						Patterns:    []string{"--share"},
-						Description: "Share file",
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
 						OmitValue:   true,
 					},
 					cli.BoolFlag{
-						Name:        "delete",
-						Patterns:    []string{"--delete"},
-						Description: "Delete local file when upload is successful",
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
 						OmitValue:   true,
 					},
-					cli.IntFlag{
-						Name:         "timeout",
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] dupes resolve [options]",
+			Description: "Remove duplicate files, keeping one file of each group",
+			Callback:    resolveDupesHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "parent",
+						Patterns:    []string{"--in"},
+						Description: "Only look for duplicates in the given directory",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also look for duplicates in subdirectories of the --in directory",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:         "keep",
+						Patterns:     []string{"--keep"},
+						Description:  "File to keep: oldest, newest or shortest-path",
+						DefaultValue: drive.KeepOldest,
+					},
+					cli.BoolFlag{
+						Name:        "shortcut",
+						Patterns:    []string{"--shortcut"},
+						Description: "Replace duplicates with shortcuts to the kept file instead of only trashing them",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "dryRun",
+						Patterns:    []string{"--dry-run"},
+						Description: "Show what would be removed without removing anything",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] dupes [options]",
+			Description: "Find files owned by you with the same content",
+			Callback:    dupesHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "parent",
+						Patterns:    []string{"--in"},
+						Description: "Only look for duplicates in the given directory",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also look for duplicates in subdirectories of the --in directory",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] verify [options] <path> <fileId>",
+			Description: "Compare a local directory with a remote directory by path and md5, exits with a non-zero status if they differ",
+			Callback:    verifyHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, needed to compare directories uploaded with --encrypt-names",
+					},
+					cli.IntFlag{
+						Name:         "parallel",
+						Patterns:     []string{"--parallel"},
+						Description:  fmt.Sprintf("Number of remote directories to list in parallel, default: %d", drive.DefaultListParallel),
+						DefaultValue: drive.DefaultListParallel,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] upload [options] <path>",
+			Description: "Upload file or directory",
+			Callback:    uploadHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Upload directory recursively",
+						OmitValue:   true,
+					},
+					cli.StringSliceFlag{
+						Name:        "parent",
+						Patterns:    []string{"-p", "--parent"},
+						Description: "Parent id, used to upload file to a specific directory, can be specified multiple times to give many parents",
+					},
+					cli.StringFlag{
+						Name:        "name",
+						Patterns:    []string{"--name"},
+						Description: "Filename",
+					},
+					cli.StringFlag{
+						Name:        "description",
+						Patterns:    []string{"--description"},
+						Description: "File description",
+					},
+					cli.BoolFlag{
+						Name:        "noProgress",
+						Patterns:    []string{"--no-progress"},
+						Description: "Hide progress",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "mime",
+						Patterns:    []string{"--mime"},
+						Description: "Force mime type",
+					},
+					cli.BoolFlag{
+						Name:        "share",
+						Patterns:    []string{"--share"},
+						Description: "Share file",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "delete",
+						Patterns:    []string{"--delete"},
+						Description: "Delete local file when upload is successful",
+						OmitValue:   true,
+					},
+					cli.IntFlag{
+						Name:         "timeout",
 						Patterns:     []string{"--timeout"},
 						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
 						DefaultValue: DefaultTimeout,
@@ -258,6 +779,39 @@ func main() {
 						Description:  fmt.Sprintf("Set chunk size in bytes, default: %d", DefaultUploadChunkSize),
 						DefaultValue: DefaultUploadChunkSize,
 					},
+					cli.BoolFlag{
+						Name:        "encrypt",
+						Patterns:    []string{"--encrypt"},
+						Description: "Encrypt file content before uploading, requires --key-file",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "encryptNames",
+						Patterns:    []string{"--encrypt-names"},
+						Description: "Encrypt file and directory names, requires --encrypt",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, at least 32 bytes",
+					},
+					cli.StringFlag{
+						Name:        "archive",
+						Patterns:    []string{"--archive"},
+						Description: "Upload directory as a single archive, tar.gz or zip. The archive is streamed, files matched by .gdriveignore are left out",
+					},
+					cli.BoolFlag{
+						Name:        "createParents",
+						Patterns:    []string{"--create-parents"},
+						Description: "Create missing directories when the parent is given as a path, i.e. drive:/backup/photos",
+						OmitValue:   true,
+					},
+					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Custom property as key=value, can be specified multiple times, applies to all uploaded files",
+					},
 				),
 			},
 		},
@@ -307,164 +861,782 @@ func main() {
 						Description: "Hide progress",
 						OmitValue:   true,
 					},
+					cli.BoolFlag{
+						Name:        "encrypt",
+						Patterns:    []string{"--encrypt"},
+						Description: "Encrypt file content before uploading, requires --key-file",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "encryptNames",
+						Patterns:    []string{"--encrypt-names"},
+						Description: "Encrypt file and directory names, requires --encrypt",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, at least 32 bytes",
+					},
+					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Custom property as key=value, can be specified multiple times",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] update [options] <fileId> <path>",
+			Description: "Update file, this creates a new revision of the file",
+			Callback:    updateHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringSliceFlag{
+						Name:        "parent",
+						Patterns:    []string{"-p", "--parent"},
+						Description: "Parent id, used to upload file to a specific directory, can be specified multiple times to give many parents",
+					},
+					cli.StringFlag{
+						Name:        "name",
+						Patterns:    []string{"--name"},
+						Description: "Filename",
+					},
+					cli.StringFlag{
+						Name:        "description",
+						Patterns:    []string{"--description"},
+						Description: "File description",
+					},
+					cli.BoolFlag{
+						Name:        "noProgress",
+						Patterns:    []string{"--no-progress"},
+						Description: "Hide progress",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "mime",
+						Patterns:    []string{"--mime"},
+						Description: "Force mime type",
+					},
+					cli.IntFlag{
+						Name:         "timeout",
+						Patterns:     []string{"--timeout"},
+						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
+						DefaultValue: DefaultTimeout,
+					},
+					cli.IntFlag{
+						Name:         "chunksize",
+						Patterns:     []string{"--chunksize"},
+						Description:  fmt.Sprintf("Set chunk size in bytes, default: %d", DefaultUploadChunkSize),
+						DefaultValue: DefaultUploadChunkSize,
+					},
+					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Custom property as key=value, can be specified multiple times",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] info [options] <fileId>",
+			Description: "Show file info",
+			Callback:    infoHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Show size in bytes",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] cat <fileId>",
+			Description: "Print the content of a file, documents are exported as text",
+			Callback:    catHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] head [options] <fileId>",
+			Description: "Print the first part of a file, documents are exported as text",
+			Callback:    headHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", rangeGlobalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "bytes",
+						Patterns:    []string{"-c", "--bytes"},
+						Description: "Print the first n bytes",
+					},
+					cli.IntFlag{
+						Name:        "lines",
+						Patterns:    []string{"-n", "--lines"},
+						Description: fmt.Sprintf("Print the first n lines, default: %d", drive.DefaultHeadLines),
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] tail [options] <fileId>",
+			Description: "Print the last part of a file, documents are exported as text",
+			Callback:    tailHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", rangeGlobalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "bytes",
+						Patterns:    []string{"-c", "--bytes"},
+						Description: "Print the last n bytes",
+					},
+					cli.IntFlag{
+						Name:        "lines",
+						Patterns:    []string{"-n", "--lines"},
+						Description: fmt.Sprintf("Print the last n lines, default: %d", drive.DefaultHeadLines),
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] mkdir [options] -p <path>",
+			Description: "Create directory and any missing parents from a path, i.e. drive:/a/b/c, and print its id",
+			Callback:    mkdirAllHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "description",
+						Patterns:    []string{"--description"},
+						Description: "Directory description",
+					},
+					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Custom property as key=value, can be specified multiple times",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] mkdir [options] <name>",
+			Description: "Create directory",
+			Callback:    mkdirHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringSliceFlag{
+						Name:        "parent",
+						Patterns:    []string{"-p", "--parent"},
+						Description: "Parent id of created directory, can be specified multiple times to give many parents",
+					},
+					cli.StringFlag{
+						Name:        "description",
+						Patterns:    []string{"--description"},
+						Description: "Directory description",
+					},
+					cli.StringSliceFlag{
+						Name:        "property",
+						Patterns:    []string{"--property"},
+						Description: "Custom property as key=value, can be specified multiple times",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] shortcut create [options] <targetId> <parent>",
+			Description: "Create shortcut to file or directory",
+			Callback:    createShortcutHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "name",
+						Patterns:    []string{"--name"},
+						Description: "Shortcut name, default: name of the target",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] meta get [options] <fileId>",
+			Description: "List properties of file",
+			Callback:    getMetaHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "app",
+						Patterns:    []string{"--app"},
+						Description: "Use the private app properties instead of the custom properties",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] meta set [options] <fileId> <property>",
+			Description: "Set property given as key=value",
+			Callback:    setMetaHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "app",
+						Patterns:    []string{"--app"},
+						Description: "Use the private app properties instead of the custom properties",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] meta unset [options] <fileId> <key>",
+			Description: "Remove property",
+			Callback:    unsetMetaHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "app",
+						Patterns:    []string{"--app"},
+						Description: "Use the private app properties instead of the custom properties",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] star <fileId>",
+			Description: "Star files, ids are comma separated or - to read from stdin",
+			Callback:    starHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] unstar <fileId>",
+			Description: "Unstar files, ids are comma separated or - to read from stdin",
+			Callback:    unstarHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] comments list [options] <fileId>",
+			Description: "List comments",
+			Callback:    listCommentsHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "unresolved",
+						Patterns:    []string{"--unresolved"},
+						Description: "Only unresolved comments",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:         "format",
+						Patterns:     []string{"--format"},
+						Description:  "Output format: table, csv or json, replies are included in json",
+						DefaultValue: drive.FormatTable,
+					},
+					cli.IntFlag{
+						Name:         "contentWidth",
+						Patterns:     []string{"--content-width"},
+						Description:  fmt.Sprintf("Width of content column, default: %d, minimum: 9, use 0 for full width", DefaultCommentWidth),
+						DefaultValue: DefaultCommentWidth,
+					},
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] comments add [options] <fileId> <text>",
+			Description: "Add comment",
+			Callback:    addCommentHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "anchor",
+						Patterns:    []string{"--anchor"},
+						Description: "Region of the file the comment refers to, as json",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] comments reply <fileId> <commentId> <text>",
+			Description: "Reply to comment",
+			Callback:    replyCommentHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] comments resolve [options] <fileId> <commentId>",
+			Description: "Resolve comment",
+			Callback:    resolveCommentHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "message",
+						Patterns:    []string{"--message"},
+						Description: "Reply posted when resolving",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] comments delete <fileId> <commentId>",
+			Description: "Delete comment",
+			Callback:    deleteCommentHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share audit [options]",
+			Description: "Report anyone links, external and discoverable shares, writers outside the allowlist and expiring permissions",
+			Callback:    shareAuditHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "parent",
+						Patterns:    []string{"--in"},
+						Description: "Only audit the given directory, default: all files owned by me",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also audit all directories below the directory given with --in",
+						OmitValue:   true,
+					},
+					cli.StringSliceFlag{
+						Name:        "domain",
+						Patterns:    []string{"--domain"},
+						Description: "Internal domain, can be specified multiple times, default: the domain of the current user",
+					},
+					cli.StringSliceFlag{
+						Name:        "allow",
+						Patterns:    []string{"--allow"},
+						Description: "Email or domain allowed to write, can be specified multiple times, writers are only checked when given",
+					},
+					cli.StringFlag{
+						Name:         "expiring",
+						Patterns:     []string{"--expiring"},
+						Description:  fmt.Sprintf("Report permissions expiring within the duration (i.e. 7d, 2w) or before the date, default: %s", drive.DefaultAuditExpiring),
+						DefaultValue: drive.DefaultAuditExpiring,
+					},
+					cli.StringFlag{
+						Name:         "format",
+						Patterns:     []string{"--format"},
+						Description:  "Output format: table, csv or json",
+						DefaultValue: drive.FormatTable,
+					},
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
+					cli.IntFlag{
+						Name:         "parallel",
+						Patterns:     []string{"--parallel"},
+						Description:  fmt.Sprintf("Number of files to list permissions of in parallel, default: %d", drive.DefaultListParallel),
+						DefaultValue: drive.DefaultListParallel,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share [options] <fileId>",
+			Description: "Share file or directory",
+			Callback:    shareHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "role",
+						Patterns:    []string{"--role"},
+						Description: fmt.Sprintf("Share role: owner/writer/commenter/reader, or organizer/fileOrganizer in shared drives, default: %s", DefaultShareRole),
+					},
+					cli.StringFlag{
+						Name:        "type",
+						Patterns:    []string{"--type"},
+						Description: fmt.Sprintf("Share type: user/group/domain/anyone, default: %s", DefaultShareType),
+					},
+					cli.StringFlag{
+						Name:        "email",
+						Patterns:    []string{"--email"},
+						Description: "The email address of the user or group to share the file with. Requires 'user' or 'group' as type",
+					},
+					cli.StringFlag{
+						Name:        "domain",
+						Patterns:    []string{"--domain"},
+						Description: "The name of Google Apps domain. Requires 'domain' as type",
+					},
+					cli.BoolFlag{
+						Name:        "discoverable",
+						Patterns:    []string{"--discoverable"},
+						Description: "Make file discoverable by search engines",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "expires",
+						Patterns:    []string{"--expires"},
+						Description: "Expiration of the permission as a date (2006-01-02) which lasts until the end of that day, datetime or duration from now (i.e. 7d, 2w, 12h)",
+					},
+					cli.BoolFlag{
+						Name:        "notify",
+						Patterns:    []string{"--notify"},
+						Description: "Send notification emails to users and groups, this is the default",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "noNotify",
+						Patterns:    []string{"--no-notify"},
+						Description: "Dont send notification emails",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "message",
+						Patterns:    []string{"--message"},
+						Description: "Message included in the notification email",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also share all files and directories below the directory, notifications are only sent for the directory",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "revoke",
+						Patterns:    []string{"--revoke"},
+						Description: "Delete all sharing permissions (owner roles will be skipped), only those matching --type, --role, --email and --domain when given",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share list <fileId>",
+			Description: "List files permissions",
+			Callback:    shareListHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share update [options] <fileId> <permission>",
+			Description: "Change role or expiration of permission given by id or email, without notifying again",
+			Callback:    shareUpdateHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.StringFlag{
+						Name:        "role",
+						Patterns:    []string{"--role"},
+						Description: "New role: writer/commenter/reader, or organizer/fileOrganizer in shared drives",
+					},
+					cli.StringFlag{
+						Name:        "expires",
+						Patterns:    []string{"--expires"},
+						Description: "New expiration as a date (2006-01-02) which lasts until the end of that day, datetime or duration from now (i.e. 7d, 2w, 12h)",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share revoke <fileId> <permission>",
+			Description: "Revoke permission given by id or email",
+			Callback:    shareRevokeHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share batch [options] <path>",
+			Description: "Apply grants from csv file with the columns fileId,type,role,email or domain,expires",
+			Callback:    shareBatchHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "notify",
+						Patterns:    []string{"--notify"},
+						Description: "Send notification emails to users and groups, this is the default",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "noNotify",
+						Patterns:    []string{"--no-notify"},
+						Description: "Dont send notification emails",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "message",
+						Patterns:    []string{"--message"},
+						Description: "Message included in the notification email",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] share copy [options] <fromId> <toId>",
+			Description: "Copy permissions to other files, ids are comma separated or - to read from stdin",
+			Callback:    shareCopyHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "notify",
+						Patterns:    []string{"--notify"},
+						Description: "Send notification emails to users and groups, this is the default",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "noNotify",
+						Patterns:    []string{"--no-notify"},
+						Description: "Dont send notification emails",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "message",
+						Patterns:    []string{"--message"},
+						Description: "Message included in the notification email",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] transfer-ownership [options] <fileId> <email>",
+			Description: "Transfer ownership of file or directory, files owned by others are skipped and running it again resumes the transfer",
+			Callback:    transferOwnershipHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Also transfer all files and directories below the directory",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "report",
+						Patterns:    []string{"--report"},
+						Description: "Write csv report of transferred, skipped and failed files to the given path",
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] delete [options] <fileId>",
+			Description: "Move file or directory to trash",
+			Callback:    deleteHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Delete directory and all it's content",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "permanent",
+						Patterns:    []string{"--permanent"},
+						Description: "Delete permanently instead of moving to trash",
+						OmitValue:   true,
+					},
 				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] update [options] <fileId> <path>",
-			Description: "Update file, this creates a new revision of the file",
-			Callback:    updateHandler,
+			Pattern:     "[global] move [options] <fileId> <parentId>",
+			Description: "Move files or directories to another directory. Give several ids separated by comma, or - to read ids from stdin. Paths can be given as drive:/path/to/file",
+			Callback:    moveHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 				cli.NewFlagGroup("options",
-					cli.StringSliceFlag{
-						Name:        "parent",
-						Patterns:    []string{"-p", "--parent"},
-						Description: "Parent id, used to upload file to a specific directory, can be specified multiple times to give many parents",
+					cli.BoolFlag{
+						Name:        "keepOtherParents",
+						Patterns:    []string{"--keep-other-parents"},
+						Description: "Add the new parent without removing the current parents",
+						OmitValue:   true,
 					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] copy [options] <fileId> <parentId>",
+			Description: "Copy file or directory to another directory, the copy is made by drive without downloading the file",
+			Callback:    copyHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
 					cli.StringFlag{
 						Name:        "name",
 						Patterns:    []string{"--name"},
-						Description: "Filename",
-					},
-					cli.StringFlag{
-						Name:        "description",
-						Patterns:    []string{"--description"},
-						Description: "File description",
+						Description: "Name of the copy",
 					},
 					cli.BoolFlag{
-						Name:        "noProgress",
-						Patterns:    []string{"--no-progress"},
-						Description: "Hide progress",
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Copy directory and all it's content",
 						OmitValue:   true,
 					},
-					cli.StringFlag{
-						Name:        "mime",
-						Patterns:    []string{"--mime"},
-						Description: "Force mime type",
+					cli.BoolFlag{
+						Name:        "export",
+						Patterns:    []string{"--export"},
+						Description: "Copy an exported version of documents that can not be copied, i.e. forms. They are skipped by default",
+						OmitValue:   true,
 					},
-					cli.IntFlag{
-						Name:         "timeout",
-						Patterns:     []string{"--timeout"},
-						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
-						DefaultValue: DefaultTimeout,
+					cli.BoolFlag{
+						Name:        "stripSync",
+						Patterns:    []string{"--strip-sync"},
+						Description: "Remove the sync properties from copies of synced files, other app properties are kept",
+						OmitValue:   true,
 					},
 					cli.IntFlag{
-						Name:         "chunksize",
-						Patterns:     []string{"--chunksize"},
-						Description:  fmt.Sprintf("Set chunk size in bytes, default: %d", DefaultUploadChunkSize),
-						DefaultValue: DefaultUploadChunkSize,
+						Name:         "parallel",
+						Patterns:     []string{"--parallel"},
+						Description:  fmt.Sprintf("Number of files to copy in parallel when copying directories, default: %d", drive.DefaultCopyParallel),
+						DefaultValue: drive.DefaultCopyParallel,
 					},
 				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] info [options] <fileId>",
-			Description: "Show file info",
-			Callback:    infoHandler,
+			Pattern:     "[global] rename undo <mappingFile>",
+			Description: "Revert a bulk rename from its mapping file",
+			Callback:    renameUndoHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
-				cli.NewFlagGroup("options",
-					cli.BoolFlag{
-						Name:        "sizeInBytes",
-						Patterns:    []string{"--bytes"},
-						Description: "Show size in bytes",
-						OmitValue:   true,
-					},
-				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] mkdir [options] <name>",
-			Description: "Create directory",
-			Callback:    mkdirHandler,
+			Pattern:     "[global] rename [options]",
+			Description: "Rename all files in a directory matching a regular expression",
+			Callback:    bulkRenameHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 				cli.NewFlagGroup("options",
-					cli.StringSliceFlag{
+					cli.StringFlag{
+						Name:        "regex",
+						Patterns:    []string{"--regex"},
+						Description: "Sed style substitution, i.e. 's/IMG_(\\d+)/photo-$1/'. Supports the g and i flags. $1 or ${1} refers to a group and $$ is a literal $, the delimiter can be escaped with a backslash",
+					},
+					cli.StringFlag{
 						Name:        "parent",
 						Patterns:    []string{"-p", "--parent"},
-						Description: "Parent id of created directory, can be specified multiple times to give many parents",
+						Description: "Id of the directory holding the files to rename",
+					},
+					cli.BoolFlag{
+						Name:        "recursive",
+						Patterns:    []string{"-r", "--recursive"},
+						Description: "Rename files in subdirectories as well",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "dryRun",
+						Patterns:    []string{"--dry-run"},
+						Description: "Show the new names without renaming anything",
+						OmitValue:   true,
 					},
 					cli.StringFlag{
-						Name:        "description",
-						Patterns:    []string{"--description"},
-						Description: "Directory description",
+						Name:        "mapping",
+						Patterns:    []string{"--mapping"},
+						Description: "Path of the mapping file used to undo the rename, default: gdrive-rename-<time>.json",
 					},
 				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] share [options] <fileId>",
-			Description: "Share file or directory",
-			Callback:    shareHandler,
+			Pattern:     "[global] rename <fileId> <name>",
+			Description: "Rename file or directory",
+			Callback:    renameHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] trash list [options]",
+			Description: "List trashed files",
+			Callback:    trashListHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 				cli.NewFlagGroup("options",
-					cli.StringFlag{
-						Name:         "role",
-						Patterns:     []string{"--role"},
-						Description:  fmt.Sprintf("Share role: owner/writer/commenter/reader, default: %s", DefaultShareRole),
-						DefaultValue: DefaultShareRole,
-					},
-					cli.StringFlag{
-						Name:         "type",
-						Patterns:     []string{"--type"},
-						Description:  fmt.Sprintf("Share type: user/group/domain/anyone, default: %s", DefaultShareType),
-						DefaultValue: DefaultShareType,
+					cli.IntFlag{
+						Name:         "maxFiles",
+						Patterns:     []string{"-m", "--max"},
+						Description:  fmt.Sprintf("Max files to list, default: %d", DefaultMaxFiles),
+						DefaultValue: DefaultMaxFiles,
 					},
 					cli.StringFlag{
-						Name:        "email",
-						Patterns:    []string{"--email"},
-						Description: "The email address of the user or group to share the file with. Requires 'user' or 'group' as type",
+						Name:        "sortOrder",
+						Patterns:    []string{"--order"},
+						Description: "Sort order. See https://godoc.org/google.golang.org/api/drive/v3#FilesListCall.OrderBy",
 					},
-					cli.StringFlag{
-						Name:        "domain",
-						Patterns:    []string{"--domain"},
-						Description: "The name of Google Apps domain. Requires 'domain' as type",
+					cli.IntFlag{
+						Name:         "nameWidth",
+						Patterns:     []string{"--name-width"},
+						Description:  fmt.Sprintf("Width of name column, default: %d, minimum: 9, use 0 for full width", DefaultNameWidth),
+						DefaultValue: DefaultNameWidth,
 					},
 					cli.BoolFlag{
-						Name:        "discoverable",
-						Patterns:    []string{"--discoverable"},
-						Description: "Make file discoverable by search engines",
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
 						OmitValue:   true,
 					},
 					cli.BoolFlag{
-						Name:        "revoke",
-						Patterns:    []string{"--revoke"},
-						Description: "Delete all sharing permissions (owner roles will be skipped)",
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
 						OmitValue:   true,
 					},
 				),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] share list <fileId>",
-			Description: "List files permissions",
-			Callback:    shareListHandler,
-			FlagGroups: cli.FlagGroups{
-				cli.NewFlagGroup("global", globalFlags...),
-			},
-		},
-		&cli.Handler{
-			Pattern:     "[global] share revoke <fileId> <permissionId>",
-			Description: "Revoke permission",
-			Callback:    shareRevokeHandler,
+			Pattern:     "[global] trash restore <fileId>",
+			Description: "Restore file or directory from trash",
+			Callback:    trashRestoreHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 			},
 		},
 		&cli.Handler{
-			Pattern:     "[global] delete [options] <fileId>",
-			Description: "Delete file or directory",
-			Callback:    deleteHandler,
+			Pattern:     "[global] trash empty [options]",
+			Description: "Permanently delete all trashed files",
+			Callback:    trashEmptyHandler,
 			FlagGroups: cli.FlagGroups{
 				cli.NewFlagGroup("global", globalFlags...),
 				cli.NewFlagGroup("options",
 					cli.BoolFlag{
-						Name:        "recursive",
-						Patterns:    []string{"-r", "--recursive"},
-						Description: "Delete directory and all it's content",
+						Name:        "force",
+						Patterns:    []string{"-f", "--force"},
+						Description: "Confirm permanent deletion of all trashed files",
 						OmitValue:   true,
 					},
 				),
@@ -550,6 +1722,16 @@ func main() {
 						Description: "Delete extraneous local files",
 						OmitValue:   true,
 					},
+					cli.StringFlag{
+						Name:        "maxDelete",
+						Patterns:    []string{"--max-delete"},
+						Description: "Abort if more than the given number or percentage of local files are extraneous, i.e. 10 or 5%",
+					},
+					cli.StringFlag{
+						Name:        "backupDir",
+						Patterns:    []string{"--backup-dir"},
+						Description: "Move replaced and deleted local files to this directory instead of overwriting or deleting them, a counter is added to the name of repeated backups",
+					},
 					cli.BoolFlag{
 						Name:        "dryRun",
 						Patterns:    []string{"--dry-run"},
@@ -568,6 +1750,11 @@ func main() {
 						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
 						DefaultValue: DefaultTimeout,
 					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, used to decrypt encrypted files",
+					},
 				),
 			},
 		},
@@ -599,9 +1786,14 @@ func main() {
 					cli.BoolFlag{
 						Name:        "deleteExtraneous",
 						Patterns:    []string{"--delete-extraneous"},
-						Description: "Delete extraneous remote files",
+						Description: "Move extraneous remote files to trash",
 						OmitValue:   true,
 					},
+					cli.StringFlag{
+						Name:        "maxDelete",
+						Patterns:    []string{"--max-delete"},
+						Description: "Abort if more than the given number or percentage of remote files are extraneous, i.e. 10 or 5%",
+					},
 					cli.BoolFlag{
 						Name:        "dryRun",
 						Patterns:    []string{"--dry-run"},
@@ -626,6 +1818,89 @@ func main() {
 						Description:  fmt.Sprintf("Set chunk size in bytes, default: %d", DefaultUploadChunkSize),
 						DefaultValue: DefaultUploadChunkSize,
 					},
+					cli.BoolFlag{
+						Name:        "encrypt",
+						Patterns:    []string{"--encrypt"},
+						Description: "Encrypt file content before uploading, requires --key-file",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "encryptNames",
+						Patterns:    []string{"--encrypt-names"},
+						Description: "Encrypt file and directory names, requires --encrypt",
+						OmitValue:   true,
+					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, at least 32 bytes",
+					},
+					cli.BoolFlag{
+						Name:        "createParents",
+						Patterns:    []string{"--create-parents"},
+						Description: "Create missing directories when the sync root is given as a path, i.e. drive:/backup/photos",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] sync run [options] --all",
+			Description: "Run all sync profiles from the config file",
+			Callback:    syncRunAllHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "dryRun",
+						Patterns:    []string{"--dry-run"},
+						Description: "Show what would have been transferred",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "noProgress",
+						Patterns:    []string{"--no-progress"},
+						Description: "Hide progress",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] sync run [options] <name>",
+			Description: "Run sync profile from the config file",
+			Callback:    syncRunHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "dryRun",
+						Patterns:    []string{"--dry-run"},
+						Description: "Show what would have been transferred",
+						OmitValue:   true,
+					},
+					cli.BoolFlag{
+						Name:        "noProgress",
+						Patterns:    []string{"--no-progress"},
+						Description: "Hide progress",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] sync profiles [options]",
+			Description: "List sync profiles from the config file",
+			Callback:    syncProfilesHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "skipHeader",
+						Patterns:    []string{"--no-header"},
+						Description: "Dont print the header",
+						OmitValue:   true,
+					},
 				),
 			},
 		},
@@ -733,6 +2008,11 @@ func main() {
 						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
 						DefaultValue: DefaultTimeout,
 					},
+					cli.StringFlag{
+						Name:        "keyFile",
+						Patterns:    []string{"--key-file"},
+						Description: "Path to file holding the encryption key, used to decrypt encrypted files",
+					},
 				),
 			},
 		},
@@ -744,6 +2024,66 @@ func main() {
 				cli.NewFlagGroup("global", globalFlags...),
 			},
 		},
+		&cli.Handler{
+			Pattern:     "[global] revision keep [options] <fileId> <revId>",
+			Description: "Keep file revision forever",
+			Callback:    keepRevisionHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "off",
+						Patterns:    []string{"--off"},
+						Description: "Stop keeping the revision forever, it may then be purged automatically",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] revision restore [options] <fileId> <revId>",
+			Description: "Make revision the current version of the file by uploading it as a new revision",
+			Callback:    restoreRevisionHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.BoolFlag{
+						Name:        "noProgress",
+						Patterns:    []string{"--no-progress"},
+						Description: "Hide progress",
+						OmitValue:   true,
+					},
+					cli.IntFlag{
+						Name:         "timeout",
+						Patterns:     []string{"--timeout"},
+						Description:  fmt.Sprintf("Set timeout in seconds, use 0 for no timeout. Timeout is reached when no data is transferred in set amount of seconds, default: %d", DefaultTimeout),
+						DefaultValue: DefaultTimeout,
+					},
+				),
+			},
+		},
+		&cli.Handler{
+			Pattern:     "[global] revision diff [options] <fileId> <revA> <revB>",
+			Description: "Show unified diff of text revisions, or size and md5 of binary revisions",
+			Callback:    diffRevisionsHandler,
+			FlagGroups: cli.FlagGroups{
+				cli.NewFlagGroup("global", globalFlags...),
+				cli.NewFlagGroup("options",
+					cli.IntFlag{
+						Name:         "context",
+						Patterns:     []string{"-U", "--context"},
+						Description:  fmt.Sprintf("Number of context lines, default: %d", drive.DefaultDiffContext),
+						DefaultValue: drive.DefaultDiffContext,
+					},
+					cli.BoolFlag{
+						Name:        "sizeInBytes",
+						Patterns:    []string{"--bytes"},
+						Description: "Size in bytes",
+						OmitValue:   true,
+					},
+				),
+			},
+		},
 		&cli.Handler{
 			Pattern:     "[global] import [options] <path>",
 			Description: "Upload and convert file to a google document, see 'about import' for available conversions",
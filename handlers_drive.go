@@ -1,12 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prasmussen/gdrive/auth"
@@ -21,11 +24,16 @@ const DefaultCacheFileName = "file_cache.json"
 
 func listHandler(ctx cli.Context) {
 	args := ctx.Args()
+	query := args.String("query")
+	if args.String("sharedDrive") != "" && query == DefaultQuery {
+		query = DefaultSharedDriveQuery
+	}
+
 	err := newDrive(args).List(drive.ListFilesArgs{
 		Out:         os.Stdout,
 		MaxFiles:    args.Int64("maxFiles"),
 		NameWidth:   args.Int64("nameWidth"),
-		Query:       args.String("query"),
+		Query:       query,
 		SortOrder:   args.String("sortOrder"),
 		SkipHeader:  args.Bool("skipHeader"),
 		SizeInBytes: args.Bool("sizeInBytes"),
@@ -34,6 +42,143 @@ func listHandler(ctx cli.Context) {
 	checkErr(err)
 }
 
+func listDrivesHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ListDrives(drive.ListDrivesArgs{
+		Out:        os.Stdout,
+		SkipHeader: args.Bool("skipHeader"),
+		Admin:      args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func createDriveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).CreateDrive(drive.CreateDriveArgs{
+		Out:  os.Stdout,
+		Name: args.String("name"),
+	})
+	checkErr(err)
+}
+
+func renameDriveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).RenameDrive(drive.RenameDriveArgs{
+		Out:   os.Stdout,
+		Id:    args.String("driveId"),
+		Name:  args.String("name"),
+		Admin: args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func hideDriveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).HideDrive(drive.HideDriveArgs{
+		Out: os.Stdout,
+		Id:  args.String("driveId"),
+	})
+	checkErr(err)
+}
+
+func unhideDriveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).HideDrive(drive.HideDriveArgs{
+		Out:    os.Stdout,
+		Id:     args.String("driveId"),
+		Unhide: true,
+	})
+	checkErr(err)
+}
+
+func deleteDriveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).DeleteDrive(drive.DeleteDriveArgs{
+		Out:   os.Stdout,
+		Id:    args.String("driveId"),
+		Admin: args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func listDriveMembersHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ListDriveMembers(drive.ListDriveMembersArgs{
+		Out:   os.Stdout,
+		Id:    args.String("driveId"),
+		Admin: args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func addDriveMemberHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).AddDriveMember(drive.AddDriveMemberArgs{
+		Out:   os.Stdout,
+		Id:    args.String("driveId"),
+		Email: args.String("email"),
+		Type:  args.String("type"),
+		Role:  args.String("role"),
+		Admin: args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func removeDriveMemberHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).RemoveDriveMember(drive.RemoveDriveMemberArgs{
+		Out:    os.Stdout,
+		Id:     args.String("driveId"),
+		Member: args.String("member"),
+		Admin:  args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func setDriveMemberRoleHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).SetDriveMemberRole(drive.SetDriveMemberRoleArgs{
+		Out:    os.Stdout,
+		Id:     args.String("driveId"),
+		Member: args.String("member"),
+		Role:   args.String("role"),
+		Admin:  args.Bool("admin"),
+	})
+	checkErr(err)
+}
+
+func treeHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Tree(drive.TreeArgs{
+		Out:         os.Stdout,
+		Id:          args.String("fileId"),
+		Depth:       int(args.Int64("depth")),
+		DirsOnly:    args.Bool("dirsOnly"),
+		Sizes:       args.Bool("sizes"),
+		SortOrder:   args.String("sortOrder"),
+		Json:        args.Bool("json"),
+		SizeInBytes: args.Bool("sizeInBytes"),
+		Parallel:    int(args.Int64("parallel")),
+	})
+	checkErr(err)
+}
+
+func duHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Du(drive.DuArgs{
+		Out:         os.Stdout,
+		Id:          args.String("fileId"),
+		Depth:       int(args.Int64("depth")),
+		Top:         int(args.Int64("top")),
+		SortOrder:   args.String("sortOrder"),
+		Format:      args.String("format"),
+		SizeInBytes: args.Bool("sizeInBytes"),
+		SkipHeader:  args.Bool("skipHeader"),
+		Parallel:    int(args.Int64("parallel")),
+	})
+	checkErr(err)
+}
+
 func listChangesHandler(ctx cli.Context) {
 	args := ctx.Args()
 	err := newDrive(args).ListChanges(drive.ListChangesArgs{
@@ -51,16 +196,19 @@ func downloadHandler(ctx cli.Context) {
 	args := ctx.Args()
 	checkDownloadArgs(args)
 	err := newDrive(args).Download(drive.DownloadArgs{
-		Out:       os.Stdout,
-		Id:        args.String("fileId"),
-		Force:     args.Bool("force"),
-		Skip:      args.Bool("skip"),
-		Path:      args.String("path"),
-		Delete:    args.Bool("delete"),
-		Recursive: args.Bool("recursive"),
-		Stdout:    args.Bool("stdout"),
-		Progress:  progressWriter(args.Bool("noProgress")),
-		Timeout:   durationInSeconds(args.Int64("timeout")),
+		Out:             os.Stdout,
+		Id:              args.String("fileId"),
+		Force:           args.Bool("force"),
+		Skip:            args.Bool("skip"),
+		Path:            args.String("path"),
+		Delete:          args.Bool("delete"),
+		Recursive:       args.Bool("recursive"),
+		Stdout:          args.Bool("stdout"),
+		Progress:        progressWriter(args.Bool("noProgress")),
+		Timeout:         durationInSeconds(args.Int64("timeout")),
+		Cipher:          keyFileCipher(args),
+		Extract:         args.Bool("extract"),
+		FollowShortcuts: args.Bool("followShortcuts"),
 	})
 	checkErr(err)
 }
@@ -75,6 +223,77 @@ func downloadQueryHandler(ctx cli.Context) {
 		Recursive: args.Bool("recursive"),
 		Path:      args.String("path"),
 		Progress:  progressWriter(args.Bool("noProgress")),
+		Cipher:    keyFileCipher(args),
+	})
+	checkErr(err)
+}
+
+func findHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkFindArgs(args)
+	err := newDrive(args).Find(drive.FindArgs{
+		Out:            os.Stdout,
+		Progress:       progressWriter(args.Bool("noProgress")),
+		Name:           args.String("name"),
+		Mime:           args.String("mime"),
+		Type:           args.String("type"),
+		Larger:         args.String("larger"),
+		ModifiedAfter:  args.String("modifiedAfter"),
+		ModifiedBefore: args.String("modifiedBefore"),
+		Owner:          args.String("owner"),
+		Shared:         args.Bool("shared"),
+		Starred:        args.Bool("starred"),
+		Properties:     propertiesArg(args),
+		ParentId:       args.String("parent"),
+		Recursive:      args.Bool("recursive"),
+		Exec:           args.String("exec"),
+		Delete:         args.Bool("delete"),
+		Download:       args.Bool("download"),
+		Path:           args.String("path"),
+		Force:          args.Bool("force"),
+		Skip:           args.Bool("skip"),
+		Cipher:         keyFileCipher(args),
+		SkipHeader:     args.Bool("skipHeader"),
+		SizeInBytes:    args.Bool("sizeInBytes"),
+	})
+	checkErr(err)
+}
+
+func dupesHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkRecursiveInArgs(args)
+	err := newDrive(args).Dupes(drive.DupesArgs{
+		Out:         os.Stdout,
+		ParentId:    args.String("parent"),
+		Recursive:   args.Bool("recursive"),
+		SizeInBytes: args.Bool("sizeInBytes"),
+	})
+	checkErr(err)
+}
+
+func resolveDupesHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkRecursiveInArgs(args)
+	err := newDrive(args).ResolveDupes(drive.ResolveDupesArgs{
+		Out:         os.Stdout,
+		ParentId:    args.String("parent"),
+		Recursive:   args.Bool("recursive"),
+		Keep:        args.String("keep"),
+		Shortcut:    args.Bool("shortcut"),
+		DryRun:      args.Bool("dryRun"),
+		SizeInBytes: args.Bool("sizeInBytes"),
+	})
+	checkErr(err)
+}
+
+func verifyHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Verify(drive.VerifyArgs{
+		Out:      os.Stdout,
+		Path:     args.String("path"),
+		Id:       args.String("fileId"),
+		Cipher:   keyFileCipher(args),
+		Parallel: int(args.Int64("parallel")),
 	})
 	checkErr(err)
 }
@@ -82,6 +301,7 @@ func downloadQueryHandler(ctx cli.Context) {
 func downloadSyncHandler(ctx cli.Context) {
 	args := ctx.Args()
 	cachePath := filepath.Join(args.String("configDir"), DefaultCacheFileName)
+	cipher := keyFileCipher(args)
 	err := newDrive(args).DownloadSync(drive.DownloadSyncArgs{
 		Out:              os.Stdout,
 		Progress:         progressWriter(args.Bool("noProgress")),
@@ -89,9 +309,12 @@ func downloadSyncHandler(ctx cli.Context) {
 		RootId:           args.String("fileId"),
 		DryRun:           args.Bool("dryRun"),
 		DeleteExtraneous: args.Bool("deleteExtraneous"),
+		MaxDelete:        deleteLimit(args),
+		BackupDir:        args.String("backupDir"),
 		Timeout:          durationInSeconds(args.Int64("timeout")),
 		Resolution:       conflictResolution(args),
-		Comparer:         NewCachedMd5Comparer(cachePath),
+		Comparer:         syncComparer(cachePath, cipher),
+		Cipher:           cipher,
 	})
 	checkErr(err)
 }
@@ -107,6 +330,7 @@ func downloadRevisionHandler(ctx cli.Context) {
 		Path:       args.String("path"),
 		Progress:   progressWriter(args.Bool("noProgress")),
 		Timeout:    durationInSeconds(args.Int64("timeout")),
+		Cipher:     keyFileCipher(args),
 	})
 	checkErr(err)
 }
@@ -114,25 +338,32 @@ func downloadRevisionHandler(ctx cli.Context) {
 func uploadHandler(ctx cli.Context) {
 	args := ctx.Args()
 	checkUploadArgs(args)
+	checkEncryptArgs(args)
 	err := newDrive(args).Upload(drive.UploadArgs{
-		Out:         os.Stdout,
-		Progress:    progressWriter(args.Bool("noProgress")),
-		Path:        args.String("path"),
-		Name:        args.String("name"),
-		Description: args.String("description"),
-		Parents:     args.StringSlice("parent"),
-		Mime:        args.String("mime"),
-		Recursive:   args.Bool("recursive"),
-		Share:       args.Bool("share"),
-		Delete:      args.Bool("delete"),
-		ChunkSize:   args.Int64("chunksize"),
-		Timeout:     durationInSeconds(args.Int64("timeout")),
+		Out:           os.Stdout,
+		Progress:      progressWriter(args.Bool("noProgress")),
+		Path:          args.String("path"),
+		Name:          args.String("name"),
+		Description:   args.String("description"),
+		Parents:       args.StringSlice("parent"),
+		Mime:          args.String("mime"),
+		Recursive:     args.Bool("recursive"),
+		Share:         args.Bool("share"),
+		Delete:        args.Bool("delete"),
+		ChunkSize:     args.Int64("chunksize"),
+		Timeout:       durationInSeconds(args.Int64("timeout")),
+		Cipher:        keyFileCipher(args),
+		EncryptName:   args.Bool("encryptNames"),
+		Archive:       args.String("archive"),
+		CreateParents: args.Bool("createParents"),
+		Properties:    propertiesArg(args),
 	})
 	checkErr(err)
 }
 
 func uploadStdinHandler(ctx cli.Context) {
 	args := ctx.Args()
+	checkEncryptArgs(args)
 	err := newDrive(args).UploadStream(drive.UploadStreamArgs{
 		Out:         os.Stdout,
 		In:          os.Stdin,
@@ -144,13 +375,18 @@ func uploadStdinHandler(ctx cli.Context) {
 		ChunkSize:   args.Int64("chunksize"),
 		Timeout:     durationInSeconds(args.Int64("timeout")),
 		Progress:    progressWriter(args.Bool("noProgress")),
+		Cipher:      keyFileCipher(args),
+		EncryptName: args.Bool("encryptNames"),
+		Properties:  propertiesArg(args),
 	})
 	checkErr(err)
 }
 
 func uploadSyncHandler(ctx cli.Context) {
 	args := ctx.Args()
+	checkEncryptArgs(args)
 	cachePath := filepath.Join(args.String("configDir"), DefaultCacheFileName)
+	cipher := keyFileCipher(args)
 	err := newDrive(args).UploadSync(drive.UploadSyncArgs{
 		Out:              os.Stdout,
 		Progress:         progressWriter(args.Bool("noProgress")),
@@ -158,14 +394,73 @@ func uploadSyncHandler(ctx cli.Context) {
 		RootId:           args.String("fileId"),
 		DryRun:           args.Bool("dryRun"),
 		DeleteExtraneous: args.Bool("deleteExtraneous"),
+		MaxDelete:        deleteLimit(args),
 		ChunkSize:        args.Int64("chunksize"),
 		Timeout:          durationInSeconds(args.Int64("timeout")),
 		Resolution:       conflictResolution(args),
-		Comparer:         NewCachedMd5Comparer(cachePath),
+		Comparer:         syncComparer(cachePath, cipher),
+		Cipher:           cipher,
+		EncryptName:      args.Bool("encryptNames"),
+		CreateParents:    args.Bool("createParents"),
 	})
 	checkErr(err)
 }
 
+func syncRunHandler(ctx cli.Context) {
+	args := ctx.Args()
+	configDir := getConfigDir(args)
+
+	config, err := readConfig(configDir)
+	checkErr(err)
+
+	profile, found := config.findSync(args.String("name"))
+	if !found {
+		ExitF("Sync '%s' is not defined in %s", args.String("name"), ConfigFilePath(configDir, ConfigFilename))
+	}
+
+	err = runSyncProfile(newDrive(args), profile, configDir, args)
+	checkErr(err)
+}
+
+func syncRunAllHandler(ctx cli.Context) {
+	args := ctx.Args()
+	configDir := getConfigDir(args)
+
+	config, err := readConfig(configDir)
+	checkErr(err)
+
+	if len(config.Syncs) == 0 {
+		ExitF("No syncs are defined in %s", ConfigFilePath(configDir, ConfigFilename))
+	}
+
+	client := newDrive(args)
+
+	// Run all syncs even if one fails, and report the failures at the end
+	var failed []string
+	for _, profile := range config.Syncs {
+		fmt.Printf("Running sync '%s'\n", profile.Name)
+
+		err = runSyncProfile(client, profile, configDir, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = append(failed, profile.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		ExitF("Failed syncs: %s", strings.Join(failed, ", "))
+	}
+}
+
+func syncProfilesHandler(ctx cli.Context) {
+	args := ctx.Args()
+
+	config, err := readConfig(getConfigDir(args))
+	checkErr(err)
+
+	printSyncProfiles(os.Stdout, config.Syncs, args.Bool("skipHeader"))
+}
+
 func updateHandler(ctx cli.Context) {
 	args := ctx.Args()
 	err := newDrive(args).Update(drive.UpdateArgs{
@@ -179,6 +474,7 @@ func updateHandler(ctx cli.Context) {
 		Progress:    progressWriter(args.Bool("noProgress")),
 		ChunkSize:   args.Int64("chunksize"),
 		Timeout:     durationInSeconds(args.Int64("timeout")),
+		Properties:  propertiesArg(args),
 	})
 	checkErr(err)
 }
@@ -193,6 +489,39 @@ func infoHandler(ctx cli.Context) {
 	checkErr(err)
 }
 
+func catHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Cat(drive.CatArgs{
+		Out: os.Stdout,
+		Id:  args.String("fileId"),
+	})
+	checkErr(err)
+}
+
+func headHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkRangeArgs(args)
+	err := newDrive(args).Head(drive.HeadArgs{
+		Out:   os.Stdout,
+		Id:    args.String("fileId"),
+		Bytes: rangeBytes(args),
+		Lines: args.Int64("lines"),
+	})
+	checkErr(err)
+}
+
+func tailHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkRangeArgs(args)
+	err := newDrive(args).Tail(drive.TailArgs{
+		Out:   os.Stdout,
+		Id:    args.String("fileId"),
+		Bytes: rangeBytes(args),
+		Lines: args.Int64("lines"),
+	})
+	checkErr(err)
+}
+
 func importHandler(ctx cli.Context) {
 	args := ctx.Args()
 	err := newDrive(args).Import(drive.ImportArgs{
@@ -236,20 +565,213 @@ func mkdirHandler(ctx cli.Context) {
 		Name:        args.String("name"),
 		Description: args.String("description"),
 		Parents:     args.StringSlice("parent"),
+		Properties:  propertiesArg(args),
+	})
+	checkErr(err)
+}
+
+func createShortcutHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).CreateShortcut(drive.CreateShortcutArgs{
+		Out:      os.Stdout,
+		TargetId: args.String("targetId"),
+		ParentId: args.String("parent"),
+		Name:     args.String("name"),
+	})
+	checkErr(err)
+}
+
+func getMetaHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).GetMeta(drive.GetMetaArgs{
+		Out:        os.Stdout,
+		Id:         args.String("fileId"),
+		App:        args.Bool("app"),
+		SkipHeader: args.Bool("skipHeader"),
+	})
+	checkErr(err)
+}
+
+func setMetaHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).SetMeta(drive.SetMetaArgs{
+		Out:      os.Stdout,
+		Id:       args.String("fileId"),
+		Property: args.String("property"),
+		App:      args.Bool("app"),
+	})
+	checkErr(err)
+}
+
+func unsetMetaHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).UnsetMeta(drive.UnsetMetaArgs{
+		Out: os.Stdout,
+		Id:  args.String("fileId"),
+		Key: args.String("key"),
+		App: args.Bool("app"),
+	})
+	checkErr(err)
+}
+
+func starHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Star(drive.StarArgs{
+		Out:     os.Stdout,
+		Ids:     fileIdList(args.String("fileId")),
+		Starred: true,
+	})
+	checkErr(err)
+}
+
+func unstarHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Star(drive.StarArgs{
+		Out: os.Stdout,
+		Ids: fileIdList(args.String("fileId")),
+	})
+	checkErr(err)
+}
+
+func mkdirAllHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).MkdirAll(drive.MkdirAllArgs{
+		Out:         os.Stdout,
+		Path:        args.String("path"),
+		Description: args.String("description"),
+		Properties:  propertiesArg(args),
 	})
 	checkErr(err)
 }
 
 func shareHandler(ctx cli.Context) {
 	args := ctx.Args()
+
+	if args.Bool("revoke") {
+		err := newDrive(args).RevokePermissions(drive.RevokePermissionsArgs{
+			Out:    os.Stdout,
+			FileId: args.String("fileId"),
+			Type:   args.String("type"),
+			Role:   args.String("role"),
+			Email:  args.String("email"),
+			Domain: args.String("domain"),
+		})
+		checkErr(err)
+		return
+	}
+
+	checkNotifyArgs(args)
 	err := newDrive(args).Share(drive.ShareArgs{
 		Out:          os.Stdout,
 		FileId:       args.String("fileId"),
-		Role:         args.String("role"),
-		Type:         args.String("type"),
+		Role:         stringDefault(args.String("role"), DefaultShareRole),
+		Type:         stringDefault(args.String("type"), DefaultShareType),
 		Email:        args.String("email"),
 		Domain:       args.String("domain"),
 		Discoverable: args.Bool("discoverable"),
+		Expires:      args.String("expires"),
+		Notify:       !args.Bool("noNotify"),
+		Message:      args.String("message"),
+		Recursive:    args.Bool("recursive"),
+	})
+	checkErr(err)
+}
+
+func listCommentsHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ListComments(drive.ListCommentsArgs{
+		Out:          os.Stdout,
+		Id:           args.String("fileId"),
+		Unresolved:   args.Bool("unresolved"),
+		Format:       args.String("format"),
+		ContentWidth: args.Int64("contentWidth"),
+		SkipHeader:   args.Bool("skipHeader"),
+	})
+	checkErr(err)
+}
+
+func addCommentHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).AddComment(drive.AddCommentArgs{
+		Out:     os.Stdout,
+		Id:      args.String("fileId"),
+		Content: args.String("text"),
+		Anchor:  args.String("anchor"),
+	})
+	checkErr(err)
+}
+
+func replyCommentHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ReplyComment(drive.ReplyCommentArgs{
+		Out:       os.Stdout,
+		Id:        args.String("fileId"),
+		CommentId: args.String("commentId"),
+		Content:   args.String("text"),
+	})
+	checkErr(err)
+}
+
+func resolveCommentHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ReplyComment(drive.ReplyCommentArgs{
+		Out:       os.Stdout,
+		Id:        args.String("fileId"),
+		CommentId: args.String("commentId"),
+		Content:   args.String("message"),
+		Resolve:   true,
+	})
+	checkErr(err)
+}
+
+func deleteCommentHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).DeleteComment(drive.DeleteCommentArgs{
+		Out:       os.Stdout,
+		Id:        args.String("fileId"),
+		CommentId: args.String("commentId"),
+	})
+	checkErr(err)
+}
+
+func shareAuditHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkRecursiveInArgs(args)
+	err := newDrive(args).ShareAudit(drive.ShareAuditArgs{
+		Out:        os.Stdout,
+		ParentId:   args.String("parent"),
+		Recursive:  args.Bool("recursive"),
+		Domains:    args.StringSlice("domain"),
+		Allow:      args.StringSlice("allow"),
+		Expiring:   args.String("expiring"),
+		Format:     args.String("format"),
+		SkipHeader: args.Bool("skipHeader"),
+		Parallel:   int(args.Int64("parallel")),
+	})
+	checkErr(err)
+}
+
+func shareBatchHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkNotifyArgs(args)
+	err := newDrive(args).ShareBatch(drive.ShareBatchArgs{
+		Out:     os.Stdout,
+		Path:    args.String("path"),
+		Notify:  !args.Bool("noNotify"),
+		Message: args.String("message"),
+	})
+	checkErr(err)
+}
+
+func shareCopyHandler(ctx cli.Context) {
+	args := ctx.Args()
+	checkNotifyArgs(args)
+	err := newDrive(args).ShareCopy(drive.ShareCopyArgs{
+		Out:     os.Stdout,
+		FromId:  args.String("fromId"),
+		ToIds:   fileIdList(args.String("toId")),
+		Notify:  !args.Bool("noNotify"),
+		Message: args.String("message"),
 	})
 	checkErr(err)
 }
@@ -268,7 +790,31 @@ func shareRevokeHandler(ctx cli.Context) {
 	err := newDrive(args).RevokePermission(drive.RevokePermissionArgs{
 		Out:          os.Stdout,
 		FileId:       args.String("fileId"),
-		PermissionId: args.String("permissionId"),
+		PermissionId: args.String("permission"),
+	})
+	checkErr(err)
+}
+
+func shareUpdateHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).UpdatePermission(drive.UpdatePermissionArgs{
+		Out:          os.Stdout,
+		FileId:       args.String("fileId"),
+		PermissionId: args.String("permission"),
+		Role:         args.String("role"),
+		Expires:      args.String("expires"),
+	})
+	checkErr(err)
+}
+
+func transferOwnershipHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).TransferOwnership(drive.TransferOwnershipArgs{
+		Out:        os.Stdout,
+		Id:         args.String("fileId"),
+		Email:      args.String("email"),
+		Recursive:  args.Bool("recursive"),
+		ReportPath: args.String("report"),
 	})
 	checkErr(err)
 }
@@ -279,6 +825,105 @@ func deleteHandler(ctx cli.Context) {
 		Out:       os.Stdout,
 		Id:        args.String("fileId"),
 		Recursive: args.Bool("recursive"),
+		Permanent: args.Bool("permanent"),
+	})
+	checkErr(err)
+}
+
+func moveHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Move(drive.MoveArgs{
+		Out:              os.Stdout,
+		Ids:              fileIdList(args.String("fileId")),
+		ParentId:         args.String("parentId"),
+		KeepOtherParents: args.Bool("keepOtherParents"),
+	})
+	checkErr(err)
+}
+
+func copyHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Copy(drive.CopyArgs{
+		Out:       os.Stdout,
+		Id:        args.String("fileId"),
+		ParentId:  args.String("parentId"),
+		Name:      args.String("name"),
+		Recursive: args.Bool("recursive"),
+		Export:    args.Bool("export"),
+		StripSync: args.Bool("stripSync"),
+		Parallel:  int(args.Int64("parallel")),
+	})
+	checkErr(err)
+}
+
+func renameHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).Rename(drive.RenameArgs{
+		Out:  os.Stdout,
+		Id:   args.String("fileId"),
+		Name: args.String("name"),
+	})
+	checkErr(err)
+}
+
+func bulkRenameHandler(ctx cli.Context) {
+	args := ctx.Args()
+	if args.String("regex") == "" || args.String("parent") == "" {
+		ExitF("Both --regex and --parent are required for bulk renames")
+	}
+
+	mappingPath := args.String("mapping")
+	if mappingPath == "" {
+		mappingPath = fmt.Sprintf("gdrive-rename-%s.json", time.Now().Format("20060102-150405"))
+	}
+
+	err := newDrive(args).BulkRename(drive.BulkRenameArgs{
+		Out:         os.Stdout,
+		Expression:  args.String("regex"),
+		ParentId:    args.String("parent"),
+		Recursive:   args.Bool("recursive"),
+		DryRun:      args.Bool("dryRun"),
+		MappingPath: mappingPath,
+	})
+	checkErr(err)
+}
+
+func renameUndoHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).UndoRename(drive.UndoRenameArgs{
+		Out:         os.Stdout,
+		MappingPath: args.String("mappingFile"),
+	})
+	checkErr(err)
+}
+
+func trashListHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).ListTrash(drive.ListTrashArgs{
+		Out:         os.Stdout,
+		MaxFiles:    args.Int64("maxFiles"),
+		NameWidth:   args.Int64("nameWidth"),
+		SortOrder:   args.String("sortOrder"),
+		SkipHeader:  args.Bool("skipHeader"),
+		SizeInBytes: args.Bool("sizeInBytes"),
+	})
+	checkErr(err)
+}
+
+func trashRestoreHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).RestoreTrash(drive.RestoreTrashArgs{
+		Out: os.Stdout,
+		Id:  args.String("fileId"),
+	})
+	checkErr(err)
+}
+
+func trashEmptyHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).EmptyTrash(drive.EmptyTrashArgs{
+		Out:   os.Stdout,
+		Force: args.Bool("force"),
 	})
 	checkErr(err)
 }
@@ -315,6 +960,43 @@ func deleteRevisionHandler(ctx cli.Context) {
 	checkErr(err)
 }
 
+func keepRevisionHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).KeepRevision(drive.KeepRevisionArgs{
+		Out:        os.Stdout,
+		FileId:     args.String("fileId"),
+		RevisionId: args.String("revId"),
+		Off:        args.Bool("off"),
+	})
+	checkErr(err)
+}
+
+func restoreRevisionHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).RestoreRevision(drive.RestoreRevisionArgs{
+		Out:        os.Stdout,
+		Progress:   progressWriter(args.Bool("noProgress")),
+		FileId:     args.String("fileId"),
+		RevisionId: args.String("revId"),
+		ChunkSize:  DefaultUploadChunkSize,
+		Timeout:    durationInSeconds(args.Int64("timeout")),
+	})
+	checkErr(err)
+}
+
+func diffRevisionsHandler(ctx cli.Context) {
+	args := ctx.Args()
+	err := newDrive(args).DiffRevisions(drive.DiffRevisionsArgs{
+		Out:         os.Stdout,
+		FileId:      args.String("fileId"),
+		RevisionA:   args.String("revA"),
+		RevisionB:   args.String("revB"),
+		Context:     int(args.Int64("context")),
+		SizeInBytes: args.Bool("sizeInBytes"),
+	})
+	checkErr(err)
+}
+
 func aboutHandler(ctx cli.Context) {
 	args := ctx.Args()
 	err := newDrive(args).About(drive.AboutArgs{
@@ -387,6 +1069,11 @@ func newDrive(args cli.Arguments) *drive.Drive {
 		ExitF("Failed getting drive: %s", err.Error())
 	}
 
+	if sharedDrive := args.String("sharedDrive"); sharedDrive != "" {
+		err = client.UseSharedDrive(sharedDrive)
+		checkErr(err)
+	}
+
 	return client
 }
 
@@ -412,6 +1099,14 @@ func progressWriter(discard bool) io.Writer {
 	return os.Stderr
 }
 
+// The default is applied here when a flag needs to know if it was given
+func stringDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 func durationInSeconds(seconds int64) time.Duration {
 	return time.Second * time.Duration(seconds)
 }
@@ -440,6 +1135,19 @@ func conflictResolution(args cli.Arguments) drive.ConflictResolution {
 	return drive.NoResolution
 }
 
+func deleteLimit(args cli.Arguments) drive.DeleteLimit {
+	if args.String("maxDelete") != "" && !args.Bool("deleteExtraneous") {
+		ExitF("--max-delete requires --delete-extraneous")
+	}
+
+	limit, err := drive.ParseDeleteLimit(args.String("maxDelete"))
+	if err != nil {
+		ExitF("%s", err)
+	}
+
+	return limit
+}
+
 func checkUploadArgs(args cli.Arguments) {
 	if args.Bool("recursive") && args.Bool("delete") {
 		ExitF("--delete is not allowed for recursive uploads")
@@ -448,10 +1156,128 @@ func checkUploadArgs(args cli.Arguments) {
 	if args.Bool("recursive") && args.Bool("share") {
 		ExitF("--share is not allowed for recursive uploads")
 	}
+
+	if args.String("archive") != "" && (args.Bool("recursive") || args.Bool("delete")) {
+		ExitF("--recursive and --delete are not allowed for archive uploads")
+	}
+}
+
+func checkFindArgs(args cli.Arguments) {
+	actions := 0
+	for _, set := range []bool{args.String("exec") != "", args.Bool("delete"), args.Bool("download")} {
+		if set {
+			actions++
+		}
+	}
+
+	if actions > 1 {
+		ExitF("Only one of --exec, --delete and --download can be given")
+	}
+
+	checkRecursiveInArgs(args)
+}
+
+func checkRecursiveInArgs(args cli.Arguments) {
+	if args.Bool("recursive") && args.String("parent") == "" {
+		ExitF("--recursive requires --in")
+	}
+}
+
+func checkRangeArgs(args cli.Arguments) {
+	if rangeBytes(args) > 0 && args.Int64("lines") > 0 {
+		ExitF("--bytes and --lines can not be given together")
+	}
+
+	if rangeBytes(args) < 0 || args.Int64("lines") < 0 {
+		ExitF("--bytes and --lines must be positive")
+	}
+}
+
+// The byte count is parsed here so a config dir given as -c is reported instead of ignored
+func rangeBytes(args cli.Arguments) int64 {
+	value := args.String("bytes")
+	if value == "" {
+		return 0
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		ExitF("Invalid byte count '%s', -c is the byte count for head and tail, use --config to give the config dir", value)
+	}
+	return n
+}
+
+func checkNotifyArgs(args cli.Arguments) {
+	if args.Bool("notify") && args.Bool("noNotify") {
+		ExitF("--notify and --no-notify can not be given together")
+	}
+
+	if args.String("message") != "" && args.Bool("noNotify") {
+		ExitF("--message requires a notification, remove --no-notify")
+	}
+}
+
+func checkEncryptArgs(args cli.Arguments) {
+	if args.Bool("encrypt") != (args.String("keyFile") != "") {
+		ExitF("--encrypt and --key-file must be given together")
+	}
+
+	if args.Bool("encryptNames") && !args.Bool("encrypt") {
+		ExitF("--encrypt-names requires --encrypt")
+	}
+}
+
+func keyFileCipher(args cli.Arguments) *drive.Cipher {
+	if args.String("keyFile") == "" {
+		return nil
+	}
+
+	cipher, err := drive.NewCipherFromKeyFile(args.String("keyFile"))
+	checkErr(err)
+	return cipher
+}
+
+func propertiesArg(args cli.Arguments) map[string]string {
+	properties, err := drive.ParseProperties(args.StringSlice("property"))
+	checkErr(err)
+	return properties
+}
+
+func syncComparer(cachePath string, cipher *drive.Cipher) drive.FileComparer {
+	if cipher != nil {
+		return NewEncryptedMd5Comparer(cachePath, cipher)
+	}
+	return NewCachedMd5Comparer(cachePath)
+}
+
+// Splits a comma separated list of file ids, or reads
+// one id per line from stdin if the list is -
+func fileIdList(s string) []string {
+	if s != "-" {
+		return strings.Split(s, ",")
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		// Only use the first column and skip the header,
+		// so the output of list can be piped in
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] != "Id" {
+			ids = append(ids, fields[0])
+		}
+	}
+	checkErr(scanner.Err())
+
+	return ids
 }
 
 func checkDownloadArgs(args cli.Arguments) {
 	if args.Bool("recursive") && args.Bool("delete") {
 		ExitF("--delete is not allowed for recursive downloads")
 	}
+
+	if args.Bool("extract") && (args.Bool("recursive") || args.Bool("stdout")) {
+		ExitF("--recursive and --stdout are not allowed when extracting archives")
+	}
 }